@@ -3,21 +3,22 @@ package e2e_test
 import (
 	"math"
 	"testing"
+	"time"
 )
 
-const (
-	expectedTotal   = 271 // 79 hail + 149 tornado + 43 wind (NOAA SPC 2024-04-26)
-	expectedHail    = 79
-	expectedTornado = 149
-	expectedWind    = 43
-
-	msgAggregationsNil = "aggregations is nil"
-)
+const msgAggregationsNil = "aggregations is nil"
 
 // fixtureTimeRange matches the mock server's fixture date (240426 → 2024-04-26).
-// Using the exact fixture date instead of a wide 2020–2030 window makes tests
+// SPC daily files cover the convective day, 12Z to 12Z, and the mock server
+// stamps rows timed 0000–1159 with the following date, so the window runs
+// from noon on the fixture date to noon the next day.
+// Using the exact fixture window instead of a wide 2020–2030 range makes tests
 // resilient to stale data from other dates that may exist in the database.
-const fixtureTimeRange = `timeRange: { from: "2024-04-26T00:00:00Z", to: "2024-04-27T00:00:00Z" }`
+const (
+	fixtureFrom      = "2024-04-26T12:00:00Z"
+	fixtureTo        = "2024-04-27T12:00:00Z"
+	fixtureTimeRange = `timeRange: { from: "` + fixtureFrom + `", to: "` + fixtureTo + `" }`
+)
 
 func TestServicesHealthy(t *testing.T) {
 	waitForHealthy(t, "api", apiURL())
@@ -31,6 +32,7 @@ func TestDataPropagation(t *testing.T) {
 
 func TestReportCounts(t *testing.T) {
 	ensureDataPropagated(t)
+	want := loadOracle(t)
 
 	query := `{
 		stormReports(filter: { ` + fixtureTimeRange + ` }) {
@@ -44,8 +46,8 @@ func TestReportCounts(t *testing.T) {
 	result := graphQLQuery(t, query)
 	sr := result.Data.StormReports
 
-	if sr.TotalCount != expectedTotal {
-		t.Errorf("totalCount = %d, want %d", sr.TotalCount, expectedTotal)
+	if sr.TotalCount != want.Total() {
+		t.Errorf("totalCount = %d, want %d", sr.TotalCount, want.Total())
 	}
 
 	if sr.Aggregations == nil {
@@ -56,15 +58,7 @@ func TestReportCounts(t *testing.T) {
 	for _, g := range sr.Aggregations.ByEventType {
 		typeCounts[g.EventType] = g.Count
 	}
-	if typeCounts["hail"] != expectedHail {
-		t.Errorf("hail count = %d, want %d", typeCounts["hail"], expectedHail)
-	}
-	if typeCounts["tornado"] != expectedTornado {
-		t.Errorf("tornado count = %d, want %d", typeCounts["tornado"], expectedTornado)
-	}
-	if typeCounts["wind"] != expectedWind {
-		t.Errorf("wind count = %d, want %d", typeCounts["wind"], expectedWind)
-	}
+	assertCounts(t, "eventType", typeCounts, want.ByType)
 }
 
 func TestStateAggregations(t *testing.T) {
@@ -88,6 +82,7 @@ func TestStateAggregations(t *testing.T) {
 		t.Fatal(msgAggregationsNil)
 	}
 	states := sr.Aggregations.ByState
+	want := loadOracle(t)
 
 	stateMap := map[string]int{}
 	for _, s := range states {
@@ -95,20 +90,14 @@ func TestStateAggregations(t *testing.T) {
 		if len(s.Counties) == 0 {
 			t.Errorf("state %s has no county breakdown", s.State)
 		}
+		countyMap := map[string]int{}
+		for _, c := range s.Counties {
+			countyMap[c.County] = c.Count
+		}
+		assertCounts(t, s.State+" county", countyMap, want.ByCounty[s.State])
 	}
 
-	if len(stateMap) != 11 {
-		t.Errorf("expected 11 states, got %d: %v", len(stateMap), stateMap)
-	}
-	if stateMap["NE"] != 100 {
-		t.Errorf("NE count = %d, want 100", stateMap["NE"])
-	}
-	if stateMap["IA"] != 69 {
-		t.Errorf("IA count = %d, want 69", stateMap["IA"])
-	}
-	if stateMap["TX"] != 39 {
-		t.Errorf("TX count = %d, want 39", stateMap["TX"])
-	}
+	assertCounts(t, "state", stateMap, want.ByState)
 }
 
 func TestReportEnrichment(t *testing.T) {
@@ -224,16 +213,30 @@ func TestHourlyAggregation(t *testing.T) {
 	}
 
 	hourTotal := 0
+	hours := map[string]int{}
 	for _, h := range sr.Aggregations.ByHour {
 		if h.Bucket == "" {
 			t.Error("hourly bucket has empty timestamp")
+			continue
+		}
+		bucket, err := time.Parse(time.RFC3339, h.Bucket)
+		if err != nil {
+			t.Errorf("hourly bucket %q is not RFC 3339: %v", h.Bucket, err)
+			continue
 		}
+		hours[bucket.UTC().Format(time.RFC3339)] += h.Count
 		hourTotal += h.Count
 	}
 
 	if hourTotal != sr.TotalCount {
 		t.Errorf("hourly bucket total = %d, totalCount = %d", hourTotal, sr.TotalCount)
 	}
+
+	wantHours := map[string]int{}
+	for bucket, n := range loadOracle(t).ByHour {
+		wantHours[bucket.Format(time.RFC3339)] = n
+	}
+	assertCounts(t, "hour", hours, wantHours)
 }
 
 func TestEventTypeFilter(t *testing.T) {
@@ -252,8 +255,8 @@ func TestEventTypeFilter(t *testing.T) {
 	result := graphQLQuery(t, query)
 	sr := result.Data.StormReports
 
-	if sr.TotalCount != expectedTornado {
-		t.Errorf("tornado filter totalCount = %d, want %d", sr.TotalCount, expectedTornado)
+	if want := loadOracle(t).ByType["tornado"]; sr.TotalCount != want {
+		t.Errorf("tornado filter totalCount = %d, want %d", sr.TotalCount, want)
 	}
 	for _, r := range sr.Reports {
 		if r.EventType != "tornado" {
@@ -306,8 +309,8 @@ func TestPagination(t *testing.T) {
 	r1 := graphQLQuery(t, page1Query)
 	sr1 := r1.Data.StormReports
 
-	if sr1.TotalCount != expectedTotal {
-		t.Errorf("page 1 totalCount = %d, want %d", sr1.TotalCount, expectedTotal)
+	if want := loadOracle(t).Total(); sr1.TotalCount != want {
+		t.Errorf("page 1 totalCount = %d, want %d", sr1.TotalCount, want)
 	}
 	if !sr1.HasMore {
 		t.Error("page 1 hasMore should be true")
@@ -366,8 +369,8 @@ func TestSeverityFilter(t *testing.T) {
 	if sr.TotalCount == 0 {
 		t.Fatal("expected at least one severe report")
 	}
-	if sr.TotalCount >= expectedTotal {
-		t.Errorf("severity filter should narrow results: got %d/%d", sr.TotalCount, expectedTotal)
+	if total := loadOracle(t).Total(); sr.TotalCount >= total {
+		t.Errorf("severity filter should narrow results: got %d/%d", sr.TotalCount, total)
 	}
 	for _, r := range sr.Reports {
 		if r.Measurement.Severity == nil || *r.Measurement.Severity != "severe" {
@@ -414,7 +417,7 @@ func TestSortByMagnitude(t *testing.T) {
 func TestGeoRadiusFilter(t *testing.T) {
 	ensureDataPropagated(t)
 
-	// Use approximate center of Nebraska (NE has 100 reports in mock data).
+	// Use approximate center of Nebraska (the state with the most reports in mock data).
 	// A 50-mile radius should return some but not all NE reports.
 	query := `{
 		stormReports(filter: {
@@ -432,8 +435,8 @@ func TestGeoRadiusFilter(t *testing.T) {
 	if sr.TotalCount == 0 {
 		t.Fatal("expected at least one report within 50 miles of central NE")
 	}
-	if sr.TotalCount >= expectedTotal {
-		t.Errorf("geo filter should narrow results: got %d/%d", sr.TotalCount, expectedTotal)
+	if total := loadOracle(t).Total(); sr.TotalCount >= total {
+		t.Errorf("geo filter should narrow results: got %d/%d", sr.TotalCount, total)
 	}
 
 	// Verify all returned reports are within ~50 miles of the center.
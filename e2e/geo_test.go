@@ -0,0 +1,83 @@
+package e2e_test
+
+import (
+	"fmt"
+	"testing"
+)
+
+// geoBoundaryMiles is how close to the radius a point may be before the test
+// stops asserting on it. The API's distance calculation may differ slightly
+// from haversine (earth model, float precision), so these are only logged.
+const geoBoundaryMiles = 0.5
+
+// TestGeoRadiusExactSet asserts the near filter returns exactly the fixture
+// rows haversine puts inside the radius, for several centers and radii.
+func TestGeoRadiusExactSet(t *testing.T) {
+	ensureDataPropagated(t)
+	rows := loadOracle(t).Rows
+
+	// noneInRange and allInRange check the oracle itself, so the extreme
+	// cases cannot silently turn into ordinary ones if fixtures change.
+	cases := []struct {
+		name                    string
+		lat, lon, miles         float64
+		noneInRange, allInRange bool
+	}{
+		{"central Nebraska", 41.0, -99.0, 50, false, false},
+		{"Omaha metro", 41.26, -95.94, 25, false, false},
+		{"San Saba", 31.02, -98.44, 10, false, false},
+		{"eastern Oregon (no reports)", 45.0, -120.0, 25, true, false},
+		{"continental US (every report)", 38.0, -98.0, 2000, false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := map[string]int{}
+			boundary := map[string]bool{}
+			for _, row := range rows {
+				k := positionKey(row.EventType, row.Time, row.Lat, row.Lon)
+				d := haversine(tc.lat, tc.lon, row.Lat, row.Lon)
+				if d > tc.miles-geoBoundaryMiles && d < tc.miles+geoBoundaryMiles {
+					boundary[k] = true
+					t.Logf("boundary point %s is %.3f miles from center", row, d)
+				}
+				if d <= tc.miles {
+					want[k]++
+				}
+			}
+			total := 0
+			for _, n := range want {
+				total += n
+			}
+			if tc.noneInRange && len(want) != 0 {
+				t.Fatalf("expected no fixture rows within %g miles, haversine finds %d", tc.miles, total)
+			}
+			if tc.allInRange && total != loadOracle(t).Total() {
+				t.Fatalf("expected every fixture row within %g miles, haversine finds %d of %d", tc.miles, total, loadOracle(t).Total())
+			}
+
+			filter := fmt.Sprintf("%s near: { lat: %g, lon: %g, radiusMiles: %g }", fixtureTimeRange, tc.lat, tc.lon, tc.miles)
+			got := map[string]int{}
+			for _, r := range fetchAllReports(t, filter, "id eventType eventTime geo { lat lon }", 100) {
+				got[positionKey(r.EventType, parseEventTime(r.EventTime), r.Geo.Lat, r.Geo.Lon)]++
+			}
+
+			for _, k := range sortedKeys(want, got) {
+				if got[k] == want[k] {
+					continue
+				}
+				if boundary[k] {
+					t.Logf("boundary point %s: got %d, haversine expects %d", k, got[k], want[k])
+					continue
+				}
+				if got[k] < want[k] {
+					t.Errorf("missing in-range report %s", k)
+				} else {
+					t.Errorf("unexpected out-of-range report %s", k)
+				}
+			}
+
+			t.Logf("%d reports within %g miles of (%g, %g)", total, tc.miles, tc.lat, tc.lon)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -20,6 +21,7 @@ var errDataReady error
 
 func ensureDataPropagated(t *testing.T) {
 	t.Helper()
+	expectedTotal := loadOracle(t).Total()
 	dataReady.Do(func() {
 		waitForHealthy(t, "api", apiURL())
 
@@ -65,6 +67,13 @@ func etlURL() string {
 	return "http://localhost:8081"
 }
 
+func mockServerURL() string {
+	if v := os.Getenv("MOCK_SERVER_URL"); v != "" {
+		return v
+	}
+	return "http://localhost:8090"
+}
+
 // waitForHealthy polls a /healthz endpoint until it returns 200 or the timeout expires.
 func waitForHealthy(t *testing.T, name, baseURL string) {
 	t.Helper()
@@ -133,6 +142,62 @@ func graphQLQuery(t *testing.T, query string) graphQLResponse {
 	return result
 }
 
+// reportFields selects every stormReport field the suite decodes.
+const reportFields = `id eventType eventTime
+	geo { lat lon }
+	measurement { magnitude unit severity }
+	location { raw name distance direction state county }
+	sourceOffice comments timeBucket processedAt`
+
+// fetchAllReports pages through stormReports for filter (the body of a
+// StormReportFilter, without limit/offset) and returns every report. It fails
+// the test if paging does not terminate within totalCount.
+func fetchAllReports(t *testing.T, filter, fields string, pageSize int) []stormReport {
+	t.Helper()
+	var all []stormReport
+	for offset := 0; ; offset += pageSize {
+		query := fmt.Sprintf(`{
+			stormReports(filter: { %s limit: %d offset: %d }) {
+				totalCount hasMore
+				reports { %s }
+			}
+		}`, filter, pageSize, offset, fields)
+		sr := graphQLQuery(t, query).Data.StormReports
+		all = append(all, sr.Reports...)
+		if !sr.HasMore || len(sr.Reports) == 0 {
+			return all
+		}
+		if len(all) > sr.TotalCount {
+			t.Fatalf("paging returned %d reports, more than totalCount %d", len(all), sr.TotalCount)
+		}
+	}
+}
+
+var (
+	reportsOnce sync.Once
+	allReports  []stormReport
+	errReports  error
+)
+
+// loadReports fetches every report in the fixture window once, ordered by
+// event time, with all fields populated. If the fetch fails, the first caller
+// fails with the fetch error and every later caller fails with errReports.
+func loadReports(t *testing.T) []stormReport {
+	t.Helper()
+	ensureDataPropagated(t)
+	reportsOnce.Do(func() {
+		// fetchAllReports stops the test on failure, so this is only
+		// cleared if the fetch completes.
+		errReports = fmt.Errorf("fetching reports failed in %s", t.Name())
+		allReports = fetchAllReports(t, fixtureTimeRange+" sortBy: EVENT_TIME sortOrder: ASC", reportFields, 100)
+		errReports = nil
+	})
+	if errReports != nil {
+		t.Fatal(errReports)
+	}
+	return allReports
+}
+
 // assertReportEnriched checks that all ETL enrichment fields are present on a report.
 func assertReportEnriched(t *testing.T, r stormReport) {
 	t.Helper()
@@ -159,6 +224,32 @@ func assertReportEnriched(t *testing.T, r stormReport) {
 	}
 }
 
+// assertCounts reports every key whose count differs between got and want.
+func assertCounts(t *testing.T, what string, got, want map[string]int) {
+	t.Helper()
+	for _, k := range sortedKeys(got, want) {
+		if got[k] != want[k] {
+			t.Errorf("%s %q count = %d, want %d", what, k, got[k], want[k])
+		}
+	}
+}
+
+// sortedKeys returns the union of the maps' keys in sorted order.
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // --- Response types ---
 
 type graphQLResponse struct {
@@ -0,0 +1,72 @@
+package e2e_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDeterministicIDs recomputes the ID of every fixture row and asserts the
+// API holds exactly that set. IDs are the dedup key for re-ingestion, so a
+// formatting change in any service (float precision, time layout, casing)
+// shows up here as a wholesale mismatch rather than as silent duplicates.
+func TestDeterministicIDs(t *testing.T) {
+	rows := loadOracle(t).Rows
+	reports := loadReports(t)
+
+	want := map[string]fixtureRow{}
+	for _, row := range rows {
+		id := expectedID(row)
+		if prev, ok := want[id]; ok {
+			t.Errorf("fixture rows collide on ID %s: %s and %s", id, prev, row)
+		}
+		want[id] = row
+	}
+
+	got := map[string]stormReport{}
+	for _, rpt := range reports {
+		if _, ok := got[rpt.ID]; ok {
+			t.Errorf("API returned ID %s more than once", rpt.ID)
+		}
+		got[rpt.ID] = rpt
+	}
+
+	for _, id := range sortedKeys(want) {
+		if _, ok := got[id]; !ok {
+			t.Errorf("missing ID %s for %s (hash input %q)", id, want[id], idInput(want[id]))
+		}
+	}
+	for _, id := range sortedKeys(got) {
+		if _, ok := want[id]; !ok {
+			rpt := got[id]
+			t.Errorf("unexpected ID %s on %s %s (%.2f, %.2f)", id, rpt.EventType, rpt.EventTime, rpt.Geo.Lat, rpt.Geo.Lon)
+		}
+	}
+}
+
+// expectedID is the hex SHA-256 of a row's identity fields, matching the ID
+// the ETL assigns.
+func expectedID(row fixtureRow) string {
+	sum := sha256.Sum256([]byte(idInput(row)))
+	return hex.EncodeToString(sum[:])
+}
+
+// idInput joins event_type|state|lat|lon|time|magnitude. Floats use the
+// shortest representation that round-trips and the time is RFC 3339 in UTC.
+func idInput(row fixtureRow) string {
+	return strings.Join([]string{
+		row.EventType,
+		row.State,
+		formatFloat(row.Lat),
+		formatFloat(row.Lon),
+		row.Time.UTC().Format(time.RFC3339),
+		formatFloat(row.magnitude()),
+	}, "|")
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
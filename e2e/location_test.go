@@ -0,0 +1,71 @@
+package e2e_test
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// TestLocationParsing parses every fixture's Location column the way the ETL
+// does and asserts the report's location block matches it exactly, including
+// null distance and direction for bare place names.
+func TestLocationParsing(t *testing.T) {
+	for _, p := range pairedReports(t) {
+		want := parseLocation(p.row.Location)
+		got := p.report.Location
+
+		if got.Raw != p.row.Location {
+			t.Errorf("%s: location.raw = %q, want %q", p.row, got.Raw, p.row.Location)
+		}
+		if got.Name != want.Name {
+			t.Errorf("%s: location.name = %q, want %q", p.row, got.Name, want.Name)
+		}
+		if g, w := formatOptional(got.Distance), formatOptional(want.Distance); g != w {
+			t.Errorf("%s: location.distance = %s, want %s", p.row, g, w)
+		}
+		if g, w := formatOptional(got.Direction), formatOptional(want.Direction); g != w {
+			t.Errorf("%s: location.direction = %s, want %s", p.row, g, w)
+		}
+		if got.State != p.row.State {
+			t.Errorf("%s: location.state = %q, want %q", p.row, got.State, p.row.State)
+		}
+		if got.County != p.row.County {
+			t.Errorf("%s: location.county = %q, want %q", p.row, got.County, p.row.County)
+		}
+	}
+}
+
+// locationPattern matches NOAA's relative locations: "8 ESE Chappel" is
+// 8 miles east-southeast of Chappel.
+var locationPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s+([NSEW]{1,3})\s+(.+)$`)
+
+// expectedLocation is the parsed form of a fixture's Location column.
+type expectedLocation struct {
+	Name      string
+	Distance  *float64
+	Direction *string
+}
+
+// parseLocation splits a relative location into distance, direction, and
+// place name. Bare place names ("Anthon") have no distance or direction.
+func parseLocation(raw string) expectedLocation {
+	m := locationPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return expectedLocation{Name: raw}
+	}
+	distance, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return expectedLocation{Name: raw}
+	}
+	direction := m[2]
+	return expectedLocation{Name: m[3], Distance: &distance, Direction: &direction}
+}
+
+// formatOptional renders a nullable field for comparison and messages.
+func formatOptional[T any](v *T) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%v", *v)
+}
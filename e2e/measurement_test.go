@@ -0,0 +1,76 @@
+package e2e_test
+
+import "testing"
+
+// measurementUnits is the unit the ETL assigns each event type.
+var measurementUnits = map[string]string{
+	"hail":    "in",
+	"wind":    "mph",
+	"tornado": "f_scale",
+}
+
+// TestMeasurementNormalization asserts the converted magnitude and unit of
+// every report: hail sizes in hundredths of an inch become inches, wind
+// speeds stay in mph, tornado ratings become their EF number, and UNK becomes
+// magnitude 0 with no severity.
+func TestMeasurementNormalization(t *testing.T) {
+	unknown := map[string]int{}
+	for _, p := range pairedReports(t) {
+		m := p.report.Measurement
+		if want := measurementUnits[p.row.EventType]; m.Unit != want {
+			t.Errorf("%s: unit = %q, want %q", p.row, m.Unit, want)
+		}
+		if want := p.row.magnitude(); formatMagnitude(m.Magnitude) != formatMagnitude(want) {
+			t.Errorf("%s: magnitude = %g, want %g (raw %q)", p.row, m.Magnitude, want, p.row.Magnitude)
+		}
+		if isUnknownMagnitude(p.row.Magnitude) {
+			unknown[p.row.EventType]++
+			if m.Magnitude != 0 {
+				t.Errorf("%s: UNK magnitude = %g, want 0", p.row, m.Magnitude)
+			}
+			if m.Severity != nil {
+				t.Errorf("%s: UNK severity = %q, want null", p.row, *m.Severity)
+			}
+		}
+	}
+	t.Logf("UNK magnitudes by type: %v", unknown)
+}
+
+// TestMaxMeasurement asserts each byEventType group's maxMeasurement is the
+// largest converted magnitude among that type's fixture rows.
+func TestMaxMeasurement(t *testing.T) {
+	ensureDataPropagated(t)
+
+	want := map[string]float64{}
+	for _, row := range loadOracle(t).Rows {
+		want[row.EventType] = max(want[row.EventType], row.magnitude())
+	}
+
+	query := `{
+		stormReports(filter: { ` + fixtureTimeRange + ` }) {
+			aggregations {
+				byEventType { eventType maxMeasurement { magnitude unit } }
+			}
+		}
+	}`
+	sr := graphQLQuery(t, query).Data.StormReports
+	if sr.Aggregations == nil {
+		t.Fatal(msgAggregationsNil)
+	}
+
+	for _, g := range sr.Aggregations.ByEventType {
+		if g.MaxMeasurement == nil {
+			// A type whose magnitudes are all UNK may have no maximum.
+			if want[g.EventType] != 0 {
+				t.Errorf("%s maxMeasurement is nil, want %g", g.EventType, want[g.EventType])
+			}
+			continue
+		}
+		if formatMagnitude(g.MaxMeasurement.Magnitude) != formatMagnitude(want[g.EventType]) {
+			t.Errorf("%s maxMeasurement.magnitude = %g, want %g", g.EventType, g.MaxMeasurement.Magnitude, want[g.EventType])
+		}
+		if unit := measurementUnits[g.EventType]; g.MaxMeasurement.Unit != unit {
+			t.Errorf("%s maxMeasurement.unit = %q, want %q", g.EventType, g.MaxMeasurement.Unit, unit)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package e2e_test
+
+import (
+	"regexp"
+	"testing"
+)
+
+// officePattern matches the issuing WFO code NOAA appends to each comment,
+// e.g. "... reported at Colorado Bend State Park. (SJT)".
+var officePattern = regexp.MustCompile(`\(([A-Z]{3})\)\s*$`)
+
+// expectedOffice returns the office code at the end of a comment, or "" when
+// the comment was truncated before it.
+func expectedOffice(comments string) string {
+	if m := officePattern.FindStringSubmatch(comments); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// TestExpectedOffice pins down how office codes are read from comments,
+// including comments truncated before the code.
+func TestExpectedOffice(t *testing.T) {
+	tests := []struct {
+		comments string
+		want     string
+	}{
+		{"Tornado reported at Colorado Bend State Park. (SJT)", "SJT"},
+		{"Trees down on power lines. (OAX) ", "OAX"},
+		{"Large hail reported by trained spotter near the intersection of", ""},
+		{"Report relayed by (EM) near town. (DMX", ""},
+		{"Office code mid-comment (FWD) then more text.", ""},
+		{"Lowercase code. (fwd)", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := expectedOffice(tt.comments); got != tt.want {
+			t.Errorf("expectedOffice(%q) = %q, want %q", tt.comments, got, tt.want)
+		}
+	}
+}
+
+// TestSourceOffice asserts every report's sourceOffice is the code from the
+// end of its fixture comment, and empty for comments that lost it to
+// truncation, then compares the per-office breakdown of every report against
+// every fixture row.
+func TestSourceOffice(t *testing.T) {
+	pairs := pairedReports(t)
+	for _, p := range pairs {
+		if office := expectedOffice(p.row.Comments); p.report.SourceOffice != office {
+			t.Errorf("%s: sourceOffice = %q, want %q (comment %q)", p.row, p.report.SourceOffice, office, p.row.Comments)
+		}
+	}
+
+	want := map[string]int{}
+	for _, row := range loadOracle(t).Rows {
+		want[expectedOffice(row.Comments)]++
+	}
+	got := map[string]int{}
+	for _, rpt := range loadReports(t) {
+		got[rpt.SourceOffice]++
+	}
+
+	assertCounts(t, "sourceOffice", got, want)
+	t.Logf("%d offices across %d reports, %d comments without an office code", len(want), len(pairs), want[""])
+}
@@ -0,0 +1,248 @@
+package e2e_test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The oracle derives every expected count from the mock server's fixture
+// CSVs, so assertions stay correct when fixtures are added or changed.
+
+// fixtureDir holds the mock server's default-scenario fixtures.
+func fixtureDir() string {
+	if v := os.Getenv("FIXTURE_DIR"); v != "" {
+		return v
+	}
+	return filepath.Join("..", "mock-server", "data")
+}
+
+// fixtureFilePattern matches NOAA report filenames: {YYMMDD}_rpts_{type}.csv
+var fixtureFilePattern = regexp.MustCompile(`^(\d{6})_rpts_(torn|hail|wind)\.csv$`)
+
+// eventTypes maps NOAA file types to the API's eventType values.
+var eventTypes = map[string]string{
+	"hail": "hail",
+	"torn": "tornado",
+	"wind": "wind",
+}
+
+// fixtureRow is one CSV row as the mock server serves it.
+type fixtureRow struct {
+	File      string
+	Line      int
+	EventType string
+	Time      time.Time // HHMM expanded with convective-day rollover, UTC
+	Magnitude string    // raw Size, F_Scale, or Speed column
+	Location  string
+	County    string
+	State     string
+	Lat       float64
+	Lon       float64
+	Comments  string
+}
+
+func (r fixtureRow) String() string {
+	return fmt.Sprintf("%s:%d (%s %s %s, %s)", r.File, r.Line, r.EventType, r.Time.Format(time.RFC3339), r.Location, r.State)
+}
+
+// magnitude converts the raw magnitude column the way the ETL does: hail size
+// from hundredths of an inch to inches, wind speed as-is in mph, and a tornado
+// (E)F rating to its number. UNK and other unparseable values become 0.
+func (r fixtureRow) magnitude() float64 {
+	raw := r.Magnitude
+	if r.EventType == "tornado" {
+		raw = strings.TrimPrefix(strings.TrimPrefix(raw, "E"), "F")
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0
+	}
+	if r.EventType == "hail" {
+		return v / 100
+	}
+	return v
+}
+
+// fixtureOracle holds the fixture rows inside the e2e time window and the
+// aggregations the API should report for them.
+type fixtureOracle struct {
+	Rows     []fixtureRow
+	ByType   map[string]int
+	ByState  map[string]int
+	ByCounty map[string]map[string]int // state → county → count
+	ByHour   map[time.Time]int
+}
+
+// Total is the number of reports the API should hold for the window.
+func (o *fixtureOracle) Total() int {
+	return len(o.Rows)
+}
+
+var (
+	oracleOnce sync.Once
+	oracle     *fixtureOracle
+	errOracle  error
+)
+
+// loadOracle parses the fixtures once and fails the calling test if they
+// cannot be read.
+func loadOracle(t *testing.T) *fixtureOracle {
+	t.Helper()
+	oracleOnce.Do(func() {
+		oracle, errOracle = buildOracle(fixtureDir(), fixtureFrom, fixtureTo)
+	})
+	if errOracle != nil {
+		t.Fatalf("building fixture oracle: %v", errOracle)
+	}
+	return oracle
+}
+
+// buildOracle reads every fixture in dir and keeps the rows whose event time
+// falls in [from, to).
+func buildOracle(dir, from, to string) (*fixtureOracle, error) {
+	start, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*_rpts_*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+
+	o := &fixtureOracle{
+		ByType:   map[string]int{},
+		ByState:  map[string]int{},
+		ByCounty: map[string]map[string]int{},
+		ByHour:   map[time.Time]int{},
+	}
+	for _, path := range files {
+		rows, err := readFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if r.Time.Before(start) || !r.Time.Before(end) {
+				continue
+			}
+			o.add(r)
+		}
+	}
+	return o, nil
+}
+
+func (o *fixtureOracle) add(r fixtureRow) {
+	o.Rows = append(o.Rows, r)
+	o.ByType[r.EventType]++
+	o.ByState[r.State]++
+	if o.ByCounty[r.State] == nil {
+		o.ByCounty[r.State] = map[string]int{}
+	}
+	o.ByCounty[r.State][r.County]++
+	o.ByHour[r.Time.Truncate(time.Hour)]++
+}
+
+// readFixture parses one NOAA report CSV.
+func readFixture(path string) ([]fixtureRow, error) {
+	name := filepath.Base(path)
+	m := fixtureFilePattern.FindStringSubmatch(name)
+	if m == nil {
+		return nil, nil
+	}
+	date, err := time.Parse("060102", m[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	f, err := os.Open(path) //nolint:gosec // path comes from a glob of the fixture directory
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := map[string]int{}
+	for i, h := range records[0] {
+		col[h] = i
+	}
+
+	rows := make([]fixtureRow, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		row, err := parseFixtureRow(rec, col, date)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, i+2, err)
+		}
+		row.File, row.Line, row.EventType = name, i+2, eventTypes[m[2]]
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseFixtureRow(rec []string, col map[string]int, date time.Time) (fixtureRow, error) {
+	get := func(name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	eventTime, err := convectiveTime(get("Time"), date)
+	if err != nil {
+		return fixtureRow{}, err
+	}
+	lat, err := strconv.ParseFloat(get("Lat"), 64)
+	if err != nil {
+		return fixtureRow{}, fmt.Errorf("lat: %w", err)
+	}
+	lon, err := strconv.ParseFloat(get("Lon"), 64)
+	if err != nil {
+		return fixtureRow{}, fmt.Errorf("lon: %w", err)
+	}
+
+	return fixtureRow{
+		Time:      eventTime,
+		Magnitude: get("Size") + get("F_Scale") + get("Speed"),
+		Location:  get("Location"),
+		County:    get("County"),
+		State:     get("State"),
+		Lat:       lat,
+		Lon:       lon,
+		Comments:  get("Comments"),
+	}, nil
+}
+
+// convectiveTime expands an HHMM time the way the mock server does: SPC
+// daily files run 12Z to 12Z, so 0000–1159 belongs to the following date.
+func convectiveTime(hhmm string, date time.Time) (time.Time, error) {
+	n, err := strconv.Atoi(hhmm)
+	if err != nil || n < 0 || n > 2359 || n%100 > 59 {
+		return time.Time{}, fmt.Errorf("invalid time %q", hhmm)
+	}
+	t := date.Add(time.Duration(n/100)*time.Hour + time.Duration(n%100)*time.Minute)
+	if n < 1200 {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,138 @@
+package e2e_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// paginationPageSizes covers a page size that leaves a short last page, the
+// dashboard's page size, and sizes near and above the fixture total.
+var paginationPageSizes = []int{7, 20, 50, 100}
+
+// TestPaginationWalk walks every page for each sort combination and page
+// size. The union must be exactly totalCount unique reports, hasMore must be
+// false on the last page only, each walk must be ordered by its sort key, and
+// every page size must yield the same sequence.
+func TestPaginationWalk(t *testing.T) {
+	ensureDataPropagated(t)
+	total := loadOracle(t).Total()
+
+	for _, sortBy := range []string{"EVENT_TIME", "MAGNITUDE"} {
+		for _, order := range []string{"ASC", "DESC"} {
+			t.Run(sortBy+"_"+order, func(t *testing.T) {
+				var first []string
+				for _, size := range paginationPageSizes {
+					reports := walkPages(t, sortBy, order, size, total)
+					assertSorted(t, sortBy, order, size, reports)
+
+					ids := make([]string, len(reports))
+					for i, r := range reports {
+						ids[i] = r.ID
+					}
+					if first == nil {
+						first = ids
+					} else if i := firstDifference(first, ids); i >= 0 {
+						t.Errorf("page size %d order differs from page size %d at position %d",
+							size, paginationPageSizes[0], i)
+					}
+				}
+			})
+		}
+	}
+}
+
+// walkPages fetches every page at the given size, checking page lengths,
+// hasMore, and that no report appears twice.
+func walkPages(t *testing.T, sortBy, order string, size, total int) []stormReport {
+	t.Helper()
+	var all []stormReport
+	seen := map[string]int{}
+	lastPage := (total - 1) / size
+
+	for page := 0; ; page++ {
+		query := fmt.Sprintf(`{
+			stormReports(filter: {
+				%s
+				sortBy: %s
+				sortOrder: %s
+				limit: %d
+				offset: %d
+			}) {
+				totalCount hasMore
+				reports { id eventTime measurement { magnitude } }
+			}
+		}`, fixtureTimeRange, sortBy, order, size, page*size)
+		sr := graphQLQuery(t, query).Data.StormReports
+
+		if sr.TotalCount != total {
+			t.Errorf("size %d page %d: totalCount = %d, want %d", size, page, sr.TotalCount, total)
+		}
+		wantLen := size
+		if page == lastPage {
+			wantLen = total - page*size
+		}
+		if len(sr.Reports) != wantLen {
+			t.Errorf("size %d page %d: %d reports, want %d", size, page, len(sr.Reports), wantLen)
+		}
+		if sr.HasMore != (page < lastPage) {
+			t.Errorf("size %d page %d of %d: hasMore = %t", size, page, lastPage, sr.HasMore)
+		}
+		for _, r := range sr.Reports {
+			if prev, dup := seen[r.ID]; dup {
+				t.Errorf("size %d: report %s on page %d was already on page %d", size, r.ID, page, prev)
+			}
+			seen[r.ID] = page
+		}
+		all = append(all, sr.Reports...)
+
+		if !sr.HasMore || len(sr.Reports) == 0 || page > lastPage {
+			break
+		}
+	}
+
+	if len(seen) != total {
+		t.Errorf("size %d: walked %d unique reports, want %d", size, len(seen), total)
+	}
+	return all
+}
+
+// assertSorted checks reports are ordered by the sort key in the given direction.
+func assertSorted(t *testing.T, sortBy, order string, size int, reports []stormReport) {
+	t.Helper()
+	key := func(r stormReport) float64 {
+		if sortBy == "MAGNITUDE" {
+			return r.Measurement.Magnitude
+		}
+		return float64(parseEventTime(r.EventTime).Unix())
+	}
+	for i := 1; i < len(reports); i++ {
+		prev, curr := key(reports[i-1]), key(reports[i])
+		if (order == "ASC" && prev > curr) || (order == "DESC" && prev < curr) {
+			t.Errorf("size %d: position %d (%s) out of %s %s order after %s",
+				size, i, describeSortKey(sortBy, reports[i]), sortBy, order, describeSortKey(sortBy, reports[i-1]))
+			return
+		}
+	}
+}
+
+func describeSortKey(sortBy string, r stormReport) string {
+	if sortBy == "MAGNITUDE" {
+		return fmt.Sprintf("magnitude %g", r.Measurement.Magnitude)
+	}
+	return parseEventTime(r.EventTime).UTC().Format(time.RFC3339)
+}
+
+// firstDifference returns the first index where a and b differ, or -1.
+func firstDifference(a, b []string) int {
+	if slices.Equal(a, b) {
+		return -1
+	}
+	for i := range min(len(a), len(b)) {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return min(len(a), len(b))
+}
@@ -0,0 +1,154 @@
+package e2e_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFullRecordReconciliation matches every report the API holds for the
+// fixture window back to the CSV row it came from, so a row dropped, added,
+// or mangled anywhere in the pipeline fails with the rows involved.
+func TestFullRecordReconciliation(t *testing.T) {
+	rows := loadOracle(t).Rows
+	reports := loadReports(t)
+
+	r := reconcile(rows, reports)
+	for _, m := range r.mismatched {
+		t.Errorf("mismatched %s\n%s", m.row, strings.Join(m.diffs, "\n"))
+	}
+	for _, row := range r.missing {
+		t.Errorf("missing from API: %s", row)
+	}
+	for _, rpt := range r.extra {
+		t.Errorf("extra in API: %s %s %s (%.2f, %.2f) magnitude %g",
+			rpt.ID, rpt.EventType, rpt.EventTime, rpt.Geo.Lat, rpt.Geo.Lon, rpt.Measurement.Magnitude)
+	}
+	if t.Failed() {
+		t.Logf("%d fixture rows, %d API reports: %d matched, %d mismatched, %d missing, %d extra",
+			len(rows), len(reports), len(r.pairs)-len(r.mismatched), len(r.mismatched), len(r.missing), len(r.extra))
+	}
+}
+
+// reconciliation is the result of pairing fixture rows with API reports.
+type reconciliation struct {
+	pairs      []recordPair // every paired row, including mismatched ones
+	mismatched []recordDiff
+	missing    []fixtureRow
+	extra      []stormReport
+}
+
+// recordPair is a fixture row and the API report it produced.
+type recordPair struct {
+	row    fixtureRow
+	report stormReport
+}
+
+// recordDiff describes a report that shares a row's type, time, and position
+// but differs in other fields.
+type recordDiff struct {
+	row   fixtureRow
+	diffs []string
+}
+
+// reconcile pairs rows with reports in two passes: first on type, time,
+// position, and magnitude, then on type, time, and position alone for rows
+// whose report was altered. Anything left over is missing or extra.
+func reconcile(rows []fixtureRow, reports []stormReport) reconciliation {
+	var r reconciliation
+
+	exact := map[string][]stormReport{}
+	for _, rpt := range reports {
+		k := reportKey(rpt)
+		exact[k] = append(exact[k], rpt)
+	}
+	var unmatched []fixtureRow
+	for _, row := range rows {
+		k := rowKey(row)
+		if len(exact[k]) == 0 {
+			unmatched = append(unmatched, row)
+			continue
+		}
+		rpt := exact[k][0]
+		exact[k] = exact[k][1:]
+		r.pairs = append(r.pairs, recordPair{row: row, report: rpt})
+		if diffs := diffRecord(row, rpt); len(diffs) > 0 {
+			r.mismatched = append(r.mismatched, recordDiff{row: row, diffs: diffs})
+		}
+	}
+
+	byPosition := map[string][]stormReport{}
+	for _, rpts := range exact {
+		for _, rpt := range rpts {
+			k := positionKey(rpt.EventType, parseEventTime(rpt.EventTime), rpt.Geo.Lat, rpt.Geo.Lon)
+			byPosition[k] = append(byPosition[k], rpt)
+		}
+	}
+	for _, row := range unmatched {
+		k := positionKey(row.EventType, row.Time, row.Lat, row.Lon)
+		if len(byPosition[k]) == 0 {
+			r.missing = append(r.missing, row)
+			continue
+		}
+		rpt := byPosition[k][0]
+		byPosition[k] = byPosition[k][1:]
+		r.pairs = append(r.pairs, recordPair{row: row, report: rpt})
+		r.mismatched = append(r.mismatched, recordDiff{row: row, diffs: diffRecord(row, rpt)})
+	}
+	for _, rpts := range byPosition {
+		r.extra = append(r.extra, rpts...)
+	}
+	return r
+}
+
+// pairedReports pairs every fixture row with its API report. Rows the API
+// lost are left out; TestFullRecordReconciliation reports those.
+func pairedReports(t *testing.T) []recordPair {
+	t.Helper()
+	return reconcile(loadOracle(t).Rows, loadReports(t)).pairs
+}
+
+// diffRecord lists the fields where a report differs from its source row.
+func diffRecord(row fixtureRow, rpt stormReport) []string {
+	var diffs []string
+	field := func(name string, want, got any) {
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			diffs = append(diffs, fmt.Sprintf("  %-9s fixture %q, api %q", name, fmt.Sprint(want), fmt.Sprint(got)))
+		}
+	}
+	field("magnitude", row.magnitude(), rpt.Measurement.Magnitude)
+	field("state", row.State, rpt.Location.State)
+	field("county", row.County, rpt.Location.County)
+	return diffs
+}
+
+func rowKey(row fixtureRow) string {
+	return positionKey(row.EventType, row.Time, row.Lat, row.Lon) + "|" + formatMagnitude(row.magnitude())
+}
+
+func reportKey(rpt stormReport) string {
+	return positionKey(rpt.EventType, parseEventTime(rpt.EventTime), rpt.Geo.Lat, rpt.Geo.Lon) +
+		"|" + formatMagnitude(rpt.Measurement.Magnitude)
+}
+
+// positionKey identifies a report by type, time, and position at the
+// fixtures' two-decimal coordinate precision.
+func positionKey(eventType string, at time.Time, lat, lon float64) string {
+	return fmt.Sprintf("%s|%s|%.2f|%.2f", eventType, at.UTC().Format(time.RFC3339), lat, lon)
+}
+
+func formatMagnitude(v float64) string {
+	return fmt.Sprintf("%.2f", math.Round(v*100)/100)
+}
+
+// parseEventTime parses an API timestamp, returning the zero time if it is not
+// RFC 3339 so the report surfaces as unmatched rather than aborting the test.
+func parseEventTime(s string) time.Time {
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
@@ -0,0 +1,193 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestReingestionIsIdempotent makes the collector fetch the fixtures a second
+// time and asserts nothing the API reports changes: the same rows produce the
+// same IDs, and the API's ON CONFLICT DO NOTHING drops the duplicates.
+//
+// COLLECTOR_RESTART_CMD is run through sh to restart the collector, which
+// collects once on startup. The mock server's request journal confirms the
+// second fetch happened before the comparison.
+func TestReingestionIsIdempotent(t *testing.T) {
+	restart := os.Getenv("COLLECTOR_RESTART_CMD")
+	if restart == "" {
+		t.Skip("COLLECTOR_RESTART_CMD not set; e.g. \"docker restart storm-data-collector\"")
+	}
+	ensureDataPropagated(t)
+
+	before := aggregationSnapshot(t)
+	since := latestJournalSeq(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "sh", "-c", restart).CombinedOutput() //nolint:gosec // command comes from the test environment
+	if err != nil {
+		t.Fatalf("restarting collector: %v\n%s", err, out)
+	}
+
+	fetched := waitForReportFetches(t, since, len(eventTypes), 3*time.Minute)
+	for _, e := range fetched {
+		if e.Status != http.StatusOK {
+			t.Errorf("re-fetch of %s returned %d", e.Path, e.Status)
+		}
+	}
+	t.Logf("collector re-fetched %d report files", len(fetched))
+
+	after := waitForStableSnapshot(t, 90*time.Second)
+	for _, k := range sortedKeys(before, after) {
+		if before[k] != after[k] {
+			t.Errorf("%s changed after re-ingestion: %q → %q", k, before[k], after[k])
+		}
+	}
+}
+
+// aggregationSnapshot flattens totalCount and every stormAggregations group
+// for the fixture window into comparable key/value pairs.
+func aggregationSnapshot(t *testing.T) map[string]string {
+	t.Helper()
+	query := `{
+		stormReports(filter: { ` + fixtureTimeRange + ` }) {
+			totalCount
+			aggregations {
+				totalCount
+				byEventType { eventType count maxMeasurement { magnitude unit } }
+				byState { state count counties { county count } }
+				byHour { bucket count }
+			}
+		}
+	}`
+	sr := graphQLQuery(t, query).Data.StormReports
+	if sr.Aggregations == nil {
+		t.Fatal(msgAggregationsNil)
+	}
+
+	snap := map[string]string{
+		"totalCount":              fmt.Sprint(sr.TotalCount),
+		"aggregations.totalCount": fmt.Sprint(sr.Aggregations.TotalCount),
+	}
+	for _, g := range sr.Aggregations.ByEventType {
+		snap["byEventType."+g.EventType] = fmt.Sprint(g.Count)
+		if g.MaxMeasurement != nil {
+			snap["byEventType."+g.EventType+".max"] = fmt.Sprintf("%g %s", g.MaxMeasurement.Magnitude, g.MaxMeasurement.Unit)
+		}
+	}
+	for _, s := range sr.Aggregations.ByState {
+		snap["byState."+s.State] = fmt.Sprint(s.Count)
+		for _, c := range s.Counties {
+			snap["byState."+s.State+"."+c.County] = fmt.Sprint(c.Count)
+		}
+	}
+	for _, h := range sr.Aggregations.ByHour {
+		snap["byHour."+h.Bucket] = fmt.Sprint(h.Count)
+	}
+	return snap
+}
+
+// waitForStableSnapshot polls the aggregations until three consecutive
+// snapshots agree, so late duplicates still in Kafka have been processed.
+func waitForStableSnapshot(t *testing.T, timeout time.Duration) map[string]string {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	snap := aggregationSnapshot(t)
+	for stable := 1; stable < 3; {
+		if time.Now().After(deadline) {
+			t.Logf("aggregations still changing after %s; comparing latest snapshot", timeout)
+			return snap
+		}
+		time.Sleep(5 * time.Second)
+		next := aggregationSnapshot(t)
+		if equalSnapshots(snap, next) {
+			stable++
+		} else {
+			stable = 1
+		}
+		snap = next
+	}
+	return snap
+}
+
+func equalSnapshots(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// journalEntry is the subset of a mock server journal entry the suite uses.
+type journalEntry struct {
+	Seq    uint64 `json:"seq"`
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Status int    `json:"status"`
+}
+
+// getJournal queries the mock server's request journal.
+func getJournal(t *testing.T, params url.Values, timeout time.Duration) []journalEntry {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mockServerURL()+"/__admin/requests?"+params.Encode(), nil)
+	if err != nil {
+		t.Fatalf("creating journal request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("journal request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("journal returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Requests []journalEntry `json:"requests"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding journal: %v", err)
+	}
+	return body.Requests
+}
+
+// latestJournalSeq returns the sequence number of the newest journaled request.
+func latestJournalSeq(t *testing.T) uint64 {
+	t.Helper()
+	var seq uint64
+	for _, e := range getJournal(t, url.Values{}, 10*time.Second) {
+		seq = max(seq, e.Seq)
+	}
+	return seq
+}
+
+// waitForReportFetches long-polls the journal until n report requests newer
+// than since have been recorded, failing the test if they never arrive.
+func waitForReportFetches(t *testing.T, since uint64, n int, timeout time.Duration) []journalEntry {
+	t.Helper()
+	params := url.Values{
+		"path":    {"/*_rpts_*.csv"},
+		"since":   {fmt.Sprint(since)},
+		"min":     {fmt.Sprint(n)},
+		"timeout": {timeout.String()},
+	}
+	entries := getJournal(t, params, timeout+10*time.Second)
+	if len(entries) < n {
+		t.Fatalf("collector made %d report requests after restart, want at least %d", len(entries), n)
+	}
+	return entries
+}
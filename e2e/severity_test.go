@@ -0,0 +1,104 @@
+package e2e_test
+
+import (
+	"strings"
+	"testing"
+)
+
+// severityThresholds is a copy of the rule-based severity classification in
+// the ETL's domain layer (github.com/couchcryptid/storm-data-etl), as shipped
+// in the brendanvinson/storm-data-etl:latest image the CI stack runs. Update
+// it with the ETL: nothing here is derived from the classifier at runtime.
+//
+// Each entry is the lowest magnitude (in the event type's unit) for a
+// severity; a report takes the highest severity whose threshold it meets.
+// Magnitude 0 means the source reported UNK and the report has no severity.
+var severityThresholds = map[string][]severityThreshold{
+	"hail": { // inches
+		{"minor", 0},
+		{"moderate", 1.0},
+		{"severe", 1.75},
+		{"extreme", 2.75},
+	},
+	"wind": { // mph
+		{"minor", 0},
+		{"moderate", 58},
+		{"severe", 75},
+		{"extreme", 90},
+	},
+	"tornado": { // EF scale
+		{"minor", 0},
+		{"moderate", 2},
+		{"severe", 3},
+		{"extreme", 5},
+	},
+}
+
+type severityThreshold struct {
+	Severity string
+	Min      float64
+}
+
+// severityLevels lists the severity filter values in ascending order.
+var severityLevels = []string{"minor", "moderate", "severe", "extreme"}
+
+// expectedSeverity classifies a row, returning "" for unknown magnitudes.
+func expectedSeverity(row fixtureRow) string {
+	if isUnknownMagnitude(row.Magnitude) {
+		return ""
+	}
+	magnitude := row.magnitude()
+	severity := ""
+	for _, th := range severityThresholds[row.EventType] {
+		if magnitude >= th.Min {
+			severity = th.Severity
+		}
+	}
+	return severity
+}
+
+// isUnknownMagnitude reports whether a raw magnitude column is NOAA's UNK.
+func isUnknownMagnitude(raw string) bool {
+	return strings.EqualFold(raw, "UNK")
+}
+
+// TestSeverityClassification asserts every report's severity matches the
+// thresholds for its event type and magnitude.
+func TestSeverityClassification(t *testing.T) {
+	for _, p := range pairedReports(t) {
+		want := expectedSeverity(p.row)
+		got := ""
+		if p.report.Measurement.Severity != nil {
+			got = *p.report.Measurement.Severity
+		}
+		if got != want {
+			t.Errorf("%s: severity = %q, want %q (magnitude %s)", p.row, got, want, p.row.Magnitude)
+		}
+	}
+}
+
+// TestSeverityFilterCounts asserts each severity filter value returns exactly
+// the reports the thresholds put in that class.
+func TestSeverityFilterCounts(t *testing.T) {
+	ensureDataPropagated(t)
+
+	want := map[string]int{}
+	for _, row := range loadOracle(t).Rows {
+		if s := expectedSeverity(row); s != "" {
+			want[s]++
+		}
+	}
+
+	for _, level := range severityLevels {
+		query := `{
+			stormReports(filter: {
+				` + fixtureTimeRange + `
+				severity: [` + strings.ToUpper(level) + `]
+			}) { totalCount }
+		}`
+		got := graphQLQuery(t, query).Data.StormReports.TotalCount
+		if got != want[level] {
+			t.Errorf("severity %s totalCount = %d, want %d", level, got, want[level])
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clock is the mock server's notion of "now". It follows the wall clock
+// until set, frozen, or advanced through the admin API, so time-dependent
+// behaviour (progressive reveal, today/yesterday, upload and touch times)
+// can be driven from tests. Request timing (latency faults, journal
+// durations) always uses the wall clock.
+type clock struct {
+	mu     sync.Mutex
+	offset time.Duration // virtual minus wall time while running
+	frozen bool
+	at     time.Time // virtual time while frozen
+	wall   func() time.Time
+}
+
+func newClock() *clock {
+	return &clock{wall: time.Now}
+}
+
+// now returns the virtual time in UTC.
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nowLocked()
+}
+
+func (c *clock) nowLocked() time.Time {
+	if c.frozen {
+		return c.at
+	}
+	return c.wall().Add(c.offset).UTC()
+}
+
+// set moves the clock to t. A frozen clock stays frozen at t; a running
+// clock keeps running from t.
+func (c *clock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.at = t.UTC()
+	c.offset = t.Sub(c.wall())
+}
+
+// setFrozen stops or restarts the clock without changing the current time.
+func (c *clock) setFrozen(frozen bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if frozen == c.frozen {
+		return
+	}
+	now := c.nowLocked()
+	c.frozen = frozen
+	c.at = now
+	c.offset = now.Sub(c.wall())
+}
+
+// advance moves the clock forward by d (backward if d is negative).
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.at = c.at.Add(d)
+	c.offset += d
+}
+
+// reset returns the clock to running wall time.
+func (c *clock) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset, c.frozen, c.at = 0, false, time.Time{}
+}
+
+// clockState is the admin view of the clock.
+type clockState struct {
+	Now    time.Time `json:"now"`
+	Frozen bool      `json:"frozen"`
+	Offset string    `json:"offset"` // virtual minus wall time
+}
+
+func (c *clock) state() clockState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowLocked()
+	return clockState{Now: now, Frozen: c.frozen, Offset: now.Sub(c.wall()).Round(time.Millisecond).String()}
+}
+
+// clockUpdate is the body of PUT /__admin/clock. Omitted fields are unchanged.
+type clockUpdate struct {
+	Now    *time.Time `json:"now"`
+	Frozen *bool      `json:"frozen"`
+}
+
+// handleGetClock shows the virtual time.
+func (s *server) handleGetClock(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.clock.state())
+}
+
+// handlePutClock sets and/or freezes the clock.
+func (s *server) handlePutClock(w http.ResponseWriter, r *http.Request) {
+	var u clockUpdate
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&u); err != nil {
+		http.Error(w, fmt.Sprintf("parsing clock: %v", err), http.StatusBadRequest)
+		return
+	}
+	if u.Now == nil && u.Frozen == nil {
+		http.Error(w, `want {"now": RFC 3339 time, "frozen": bool}`, http.StatusBadRequest)
+		return
+	}
+	if u.Frozen != nil {
+		s.clock.setFrozen(*u.Frozen)
+	}
+	if u.Now != nil {
+		s.clock.set(*u.Now)
+	}
+	st := s.clock.state()
+	log.Printf("admin: clock set to %s (frozen=%t)", st.Now.Format(time.RFC3339), st.Frozen)
+	writeJSON(w, http.StatusOK, st)
+}
+
+// handleAdvanceClock moves the clock by ?by=, a Go duration such as 90m.
+func (s *server) handleAdvanceClock(w http.ResponseWriter, r *http.Request) {
+	d, err := time.ParseDuration(r.URL.Query().Get("by"))
+	if err != nil {
+		http.Error(w, "by must be a duration such as 30m or 2h", http.StatusBadRequest)
+		return
+	}
+	s.clock.advance(d)
+	st := s.clock.state()
+	log.Printf("admin: clock advanced %s to %s", d, st.Now.Format(time.RFC3339))
+	writeJSON(w, http.StatusOK, st)
+}
+
+// handleResetClock returns the clock to running wall time.
+func (s *server) handleResetClock(w http.ResponseWriter, _ *http.Request) {
+	s.clock.reset()
+	log.Printf("admin: clock reset to wall time")
+	writeJSON(w, http.StatusOK, s.clock.state())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeWall is a wall clock that only moves when told to.
+type fakeWall struct{ t time.Time }
+
+func (w *fakeWall) now() time.Time { return w.t }
+
+func newTestClock(wallStart time.Time) (*clock, *fakeWall) {
+	wall := &fakeWall{t: wallStart}
+	return &clock{wall: wall.now}, wall
+}
+
+func TestClock(t *testing.T) {
+	wallStart := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	virtual := time.Date(2024, 4, 26, 18, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		// run drives the clock; the wall clock moves an hour between
+		// each call and the final check.
+		run  func(c *clock)
+		want time.Time
+	}{
+		{
+			name: "follows the wall clock by default",
+			run:  func(*clock) {},
+			want: wallStart.Add(time.Hour),
+		},
+		{
+			name: "set keeps running from the new time",
+			run:  func(c *clock) { c.set(virtual) },
+			want: virtual.Add(time.Hour),
+		},
+		{
+			name: "frozen clock stays put",
+			run:  func(c *clock) { c.set(virtual); c.setFrozen(true) },
+			want: virtual,
+		},
+		{
+			name: "set while frozen stays frozen",
+			run:  func(c *clock) { c.setFrozen(true); c.set(virtual) },
+			want: virtual,
+		},
+		{
+			name: "advance a frozen clock",
+			run:  func(c *clock) { c.set(virtual); c.setFrozen(true); c.advance(90 * time.Minute) },
+			want: virtual.Add(90 * time.Minute),
+		},
+		{
+			name: "advance a running clock",
+			run:  func(c *clock) { c.set(virtual); c.advance(-30 * time.Minute) },
+			want: virtual.Add(30 * time.Minute),
+		},
+		{
+			name: "reset returns to wall time",
+			run:  func(c *clock) { c.set(virtual); c.setFrozen(true); c.reset() },
+			want: wallStart.Add(time.Hour),
+		},
+		{
+			name: "set converts to UTC",
+			run:  func(c *clock) { c.setFrozen(true); c.set(virtual.In(time.FixedZone("CDT", -5*3600))) },
+			want: virtual,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, wall := newTestClock(wallStart)
+			tc.run(c)
+			wall.t = wall.t.Add(time.Hour)
+			got := c.now()
+			if !got.Equal(tc.want) || got.Location() != time.UTC {
+				t.Errorf("now() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClockUnfreezeResumesFromFrozenTime(t *testing.T) {
+	wallStart := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	virtual := time.Date(2024, 4, 26, 18, 0, 0, 0, time.UTC)
+	c, wall := newTestClock(wallStart)
+
+	c.set(virtual)
+	c.setFrozen(true)
+	wall.t = wall.t.Add(time.Hour)
+	c.setFrozen(false)
+	wall.t = wall.t.Add(time.Minute)
+
+	if got, want := c.now(), virtual.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("now() = %s, want %s", got, want)
+	}
+	st := c.state()
+	if st.Frozen || st.Offset != virtual.Sub(wallStart.Add(time.Hour)).String() {
+		t.Errorf("state() = %+v", st)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+)
+
+// combinedSections is the order SPC lists report types in combined files.
+var combinedSections = []string{"torn", "wind", "hail"}
+
+// report is a response body ready to serve.
+type report struct {
+	data    []byte
+	modTime time.Time
+}
+
+// reportFor renders the report a request asks for: a single fixture, or for
+// a combinedReport key every type's section in one file. It returns nil and
+// no error when the date has no data.
+func (s *server) reportFor(r *http.Request, scenario string, key fixtureKey) (*report, error) {
+	convectiveDay, err := s.convectiveDayFor(r)
+	if err != nil {
+		return nil, err
+	}
+	if key.Type == combinedReport {
+		return s.combinedReportFor(r, scenario, key.Date, convectiveDay)
+	}
+	f, err := s.fixtureFor(r, scenario, key)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	return s.renderReport(r, revealName(scenario, key, ""), f, convectiveDay)
+}
+
+// revealName names a requested file, or one section of a combined file, for
+// fetch-mode reveal progress. Counting by the requested file keeps a combined
+// file and its per-type files (or a date served from FALLBACK_DATE) from
+// advancing each other.
+func revealName(scenario string, key fixtureKey, section string) string {
+	name := scenario + "/" + key.String()
+	if section != "" {
+		name += "#" + section
+	}
+	return name
+}
+
+// renderReport trims a fixture to the rows revealed so far under name and
+// renders it.
+func (s *server) renderReport(r *http.Request, name string, f *fixture, convectiveDay bool) (*report, error) {
+	f, err := s.reveal(r, name, f, convectiveDay)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.render(r, f, convectiveDay)
+	if err != nil {
+		return nil, err
+	}
+	return &report{data: data, modTime: f.modTime}, nil
+}
+
+// combinedReportFor assembles a combined file from the date's tornado, wind,
+// and hail fixtures, each section starting with its own header row. A type
+// with no fixture contributes a header-only section, as SPC's files do on
+// days without that type of report. Last-Modified is the newest section's.
+func (s *server) combinedReportFor(r *http.Request, scenario, date string, convectiveDay bool) (*report, error) {
+	var (
+		buf   bytes.Buffer
+		rep   report
+		found bool
+	)
+	for _, typ := range combinedSections {
+		f, err := s.fixtureFor(r, scenario, fixtureKey{Date: date, Type: typ})
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			buf.WriteString(reportHeaders[typ] + "\n")
+			continue
+		}
+		name := revealName(scenario, fixtureKey{Date: date, Type: combinedReport}, typ)
+		section, err := s.renderReport(r, name, f, convectiveDay)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(section.data)
+		if len(section.data) > 0 && section.data[len(section.data)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		found = true
+		if section.modTime.After(rep.modTime) {
+			rep.modTime = section.modTime
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	rep.data = buf.Bytes()
+	return &rep, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCombinedReportFor(t *testing.T) {
+	date := time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2024, 4, 27, 1, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 4, 27, 2, 0, 0, 0, time.UTC)
+	fixtureOf := func(typ, data string, modTime time.Time) *fixture {
+		return &fixture{key: fixtureKey{Date: "240426", Type: typ}, date: date, data: []byte(data), modTime: modTime}
+	}
+
+	const (
+		torn = "Time,F_Scale,Location\n1223,UNK,Here\n"
+		wind = "Time,Speed,Location\n1245,65,There" // no trailing newline
+		hail = "Time,Size,Location\n1510,125,Elsewhere\n"
+	)
+
+	cases := []struct {
+		name        string
+		fixtures    []*fixture
+		want        string // "" when no report is expected
+		wantModTime time.Time
+	}{
+		{
+			name:        "every type, in torn, wind, hail order",
+			fixtures:    []*fixture{fixtureOf("hail", hail, older), fixtureOf("wind", wind, newer), fixtureOf("torn", torn, older)},
+			want:        torn + wind + "\n" + hail,
+			wantModTime: newer,
+		},
+		{
+			name:        "missing type gets a header-only section",
+			fixtures:    []*fixture{fixtureOf("torn", torn, newer), fixtureOf("hail", hail, older)},
+			want:        torn + reportHeaders["wind"] + "\n" + hail,
+			wantModTime: newer,
+		},
+		{
+			name:        "single type",
+			fixtures:    []*fixture{fixtureOf("hail", hail, older)},
+			want:        reportHeaders["torn"] + "\n" + reportHeaders["wind"] + "\n" + hail,
+			wantModTime: older,
+		},
+		{
+			name:     "no type",
+			fixtures: nil,
+			want:     "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fixtureStore{scenarios: map[string]map[fixtureKey]*fixture{defaultScenario: {}}}
+			for _, f := range tc.fixtures {
+				store.put(defaultScenario, f)
+			}
+			srv := &server{
+				fixtures:        store,
+				defaultScenario: defaultScenario,
+				serveMode:       serveModeRaw,
+				revealMode:      revealConfig{Mode: revealOff},
+				clock:           newClock(),
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/240426_rpts.csv", http.NoBody)
+			rep, err := srv.combinedReportFor(r, defaultScenario, "240426", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want == "" {
+				if rep != nil {
+					t.Fatalf("combinedReportFor = %q, want nil", rep.data)
+				}
+				return
+			}
+			if rep == nil {
+				t.Fatal("combinedReportFor = nil")
+			}
+			if string(rep.data) != tc.want {
+				t.Errorf("body:\ngot  %q\nwant %q", rep.data, tc.want)
+			}
+			if !rep.modTime.Equal(tc.wantModTime) {
+				t.Errorf("modTime = %s, want %s", rep.modTime, tc.wantModTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etagFor returns a strong entity tag for a response body and its
+// modification time. Expanded and raw renderings of the same fixture get
+// different tags, as they should. Like Apache's, the tag includes the mtime,
+// so touching a fixture changes it even though the bytes are the same.
+func etagFor(body []byte, modTime time.Time) string {
+	sum := sha256.Sum256(body)
+	tag := hex.EncodeToString(sum[:])
+	if !modTime.IsZero() {
+		tag += "-" + strconv.FormatInt(modTime.UnixNano(), 16)
+	}
+	return `"` + tag + `"`
+}
+
+// setValidators sets ETag and, when the fixture has a modification time,
+// Last-Modified.
+func setValidators(w http.ResponseWriter, etag string, modTime time.Time) {
+	w.Header().Set("ETag", etag)
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since for a GET or HEAD.
+// As in RFC 9110, If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modTime.IsZero() {
+		return false
+	}
+	// HTTP dates have one-second resolution.
+	return !modTime.Truncate(time.Second).After(ims)
+}
+
+// etagMatches reports whether an If-None-Match list matches etag using the
+// weak comparison the header calls for.
+func etagMatches(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified sends a 304 with the validators already set on w.
+func writeNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETagFor(t *testing.T) {
+	body := []byte("Time,Location\n1510,Here\n")
+	modTime := time.Date(2024, 4, 26, 15, 10, 0, 0, time.UTC)
+
+	if etagFor(body, modTime) != etagFor(body, modTime) {
+		t.Error("same body and modTime gave different tags")
+	}
+	if etagFor(body, modTime) == etagFor(body, modTime.Add(time.Second)) {
+		t.Error("touching the fixture kept the same tag")
+	}
+	if etagFor(body, modTime) == etagFor([]byte("Time,Location\n"), modTime) {
+		t.Error("different bodies gave the same tag")
+	}
+	if etagFor(body, time.Time{}) == etagFor(body, modTime) {
+		t.Error("a report without a modTime shares a tag with one that has it")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"abc"`
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz",W/"abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{`abc`, false},
+		{`"ABC"`, false},
+		{`""`, false},
+	}
+	for _, tc := range cases {
+		if got := etagMatches(tc.header, etag); got != tc.want {
+			t.Errorf("etagMatches(%s) = %t, want %t", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"abc"`
+	modTime := time.Date(2024, 4, 26, 15, 10, 0, 500_000_000, time.UTC)
+	atModTime := modTime.Format(http.TimeFormat) // truncated to the second
+	before := modTime.Add(-time.Minute).Format(http.TimeFormat)
+
+	cases := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		modTime time.Time
+		want    bool
+	}{
+		{"no validators", http.MethodGet, nil, modTime, false},
+		{"matching etag", http.MethodGet, map[string]string{"If-None-Match": etag}, modTime, true},
+		{"matching etag on HEAD", http.MethodHead, map[string]string{"If-None-Match": etag}, modTime, true},
+		{"matching etag on POST", http.MethodPost, map[string]string{"If-None-Match": etag}, modTime, false},
+		{"stale etag", http.MethodGet, map[string]string{"If-None-Match": `"old"`}, modTime, false},
+		{"since modTime", http.MethodGet, map[string]string{"If-Modified-Since": atModTime}, modTime, true},
+		{"since before modTime", http.MethodGet, map[string]string{"If-Modified-Since": before}, modTime, false},
+		{"since without modTime", http.MethodGet, map[string]string{"If-Modified-Since": atModTime}, time.Time{}, false},
+		{"unparseable since", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, modTime, false},
+		{"stale etag overrides a fresh since", http.MethodGet, map[string]string{"If-None-Match": `"old"`, "If-Modified-Since": atModTime}, modTime, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/240426_rpts_torn.csv", http.NoBody)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			if got := notModified(r, etag, tc.modTime); got != tc.want {
+				t.Errorf("notModified = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// config is the server's startup configuration, read from the environment.
+type config struct {
+	DataDir       string
+	Port          string
+	Scenario      string
+	FallbackDate  string
+	ServeMode     string
+	ConvectiveDay bool
+	Generator     generatorConfig
+	Reveal        revealConfig
+	ClockStart    time.Time // zero leaves the clock on wall time
+	ClockFrozen   bool
+	JournalSize   int
+	ScriptFile    string
+	Faults        faultConfig
+}
+
+// configFromEnv reads the server configuration, applying defaults for unset
+// variables.
+func configFromEnv() (config, error) {
+	c := config{
+		DataDir:       envOr("DATA_DIR", "/data"),
+		Port:          envOr("PORT", "8080"),
+		Scenario:      envOr("DEFAULT_SCENARIO", defaultScenario),
+		ServeMode:     serveModeExpanded,
+		ConvectiveDay: true,
+		Generator:     generatorConfig{Seed: 1},
+		Reveal:        revealConfig{Mode: revealOff},
+		JournalSize:   1000,
+		ScriptFile:    os.Getenv("SCRIPT_FILE"),
+	}
+	options := []struct {
+		env string
+		set func(v string) error
+	}{
+		{"SERVE_MODE", setFrom(&c.ServeMode, parseServeMode)},
+		{"CONVECTIVE_DAY", setFrom(&c.ConvectiveDay, parseBool)},
+		{"FALLBACK_DATE", setFrom(&c.FallbackDate, parseFallbackDate)},
+		{"GENERATOR_SEED", setFrom(&c.Generator.Seed, parseSeed)},
+		{"GENERATOR_ROWS", setFrom(&c.Generator.Rows, parseGeneratedRows)},
+		{"REVEAL", setFrom(&c.Reveal, parseReveal)},
+		{"CLOCK_START", setFrom(&c.ClockStart, parseTime)},
+		{"CLOCK_FROZEN", setFrom(&c.ClockFrozen, parseBool)},
+		{"JOURNAL_SIZE", setFrom(&c.JournalSize, parsePositive)},
+	}
+	for _, opt := range options {
+		if v := os.Getenv(opt.env); v != "" {
+			if err := opt.set(v); err != nil {
+				return config{}, fmt.Errorf("invalid %s: %w", opt.env, err)
+			}
+		}
+	}
+
+	faults, err := faultsFromEnv()
+	if err != nil {
+		return config{}, fmt.Errorf("invalid fault config: %w", err)
+	}
+	c.Faults = faults
+	return c, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// setFrom returns a setter that parses a value into dst.
+func setFrom[T any](dst *T, parse func(string) (T, error)) func(string) error {
+	return func(v string) error {
+		parsed, err := parse(v)
+		if err != nil {
+			return err
+		}
+		*dst = parsed
+		return nil
+	}
+}
+
+func parseBool(v string) (bool, error) {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%q: want true or false", v)
+	}
+	return b, nil
+}
+
+func parseFallbackDate(v string) (string, error) {
+	if _, err := time.Parse("060102", v); err != nil {
+		return "", fmt.Errorf("%q: want YYMMDD", v)
+	}
+	return v, nil
+}
+
+func parseSeed(v string) (uint64, error) {
+	seed, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q: want an unsigned integer", v)
+	}
+	return seed, nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: want an RFC 3339 time", v)
+	}
+	return t, nil
+}
+
+func parsePositive(v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q: want a positive integer", v)
+	}
+	return n, nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// faultOptions are the fault settings accepted from FAULT_* env vars,
+// fault_* query parameters, and X-Mock-Fault-* headers.
+var faultOptions = []string{"latency", "error_rate", "error_status", "reset_rate", "truncate_rate", "trickle"}
+
+// faultConfig describes failures injected into report responses so the
+// collector's retry strategy can be exercised. The zero value injects nothing.
+type faultConfig struct {
+	LatencyMin time.Duration // fixed delay, or lower bound of a random delay
+	LatencyMax time.Duration // upper bound of a random delay; equals LatencyMin when fixed
+
+	ErrorRate   float64 // probability of answering with ErrorStatus
+	ErrorStatus int
+
+	ResetRate    float64 // probability of resetting the TCP connection
+	TruncateRate float64 // probability of cutting the body off mid-row
+
+	TrickleBytes    int           // bytes written per TrickleInterval; 0 disables
+	TrickleInterval time.Duration // pause between trickled chunks
+}
+
+// faultsFromEnv reads FAULT_LATENCY, FAULT_ERROR_RATE, etc.
+func faultsFromEnv() (faultConfig, error) {
+	c := faultConfig{ErrorStatus: http.StatusServiceUnavailable}
+	for _, name := range faultOptions {
+		env := "FAULT_" + strings.ToUpper(name)
+		if v := os.Getenv(env); v != "" {
+			if err := c.set(name, v); err != nil {
+				return faultConfig{}, fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// withRequest returns a copy of c with any fault_* query or X-Mock-Fault-*
+// header overrides from r applied.
+func (c faultConfig) withRequest(r *http.Request) (faultConfig, error) {
+	for _, name := range faultOptions {
+		if v := requestOption(r, "fault_"+name); v != "" {
+			if err := c.set(name, v); err != nil {
+				return faultConfig{}, fmt.Errorf("fault_%s: %w", name, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// withOptions returns a copy of c with the named options applied,
+// e.g. {"latency": "2s"} from a scripted step.
+func (c faultConfig) withOptions(opts map[string]string) (faultConfig, error) {
+	for name, v := range opts {
+		if err := c.set(name, v); err != nil {
+			return faultConfig{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return c, nil
+}
+
+// set parses a single fault option.
+//
+//	latency:       "250ms" (fixed) or "100ms-2s" (uniform random)
+//	*_rate:        probability between 0 and 1
+//	error_status:  HTTP status code, 400–599
+//	trickle:       "{bytes}/{interval}", e.g. "64/100ms"
+func (c *faultConfig) set(name, value string) error {
+	var err error
+	switch name {
+	case "latency":
+		c.LatencyMin, c.LatencyMax, err = parseLatency(value)
+	case "error_rate":
+		c.ErrorRate, err = parseRate(value)
+	case "error_status":
+		c.ErrorStatus, err = strconv.Atoi(value)
+		if err == nil && (c.ErrorStatus < 400 || c.ErrorStatus > 599) {
+			err = fmt.Errorf("status %d is not an error status", c.ErrorStatus)
+		}
+	case "reset_rate":
+		c.ResetRate, err = parseRate(value)
+	case "truncate_rate":
+		c.TruncateRate, err = parseRate(value)
+	case "trickle":
+		c.TrickleBytes, c.TrickleInterval, err = parseTrickle(value)
+	default:
+		err = fmt.Errorf("unknown fault option %q", name)
+	}
+	return err
+}
+
+func parseLatency(v string) (lo, hi time.Duration, err error) {
+	loStr, hiStr, isRange := strings.Cut(v, "-")
+	if lo, err = time.ParseDuration(loStr); err != nil {
+		return 0, 0, err
+	}
+	hi = lo
+	if isRange {
+		if hi, err = time.ParseDuration(hiStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	if lo < 0 || hi < lo {
+		return 0, 0, fmt.Errorf("invalid latency range %q", v)
+	}
+	return lo, hi, nil
+}
+
+func parseRate(v string) (float64, error) {
+	rate, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %v outside [0, 1]", rate)
+	}
+	return rate, nil
+}
+
+func parseTrickle(v string) (int, time.Duration, error) {
+	sizeStr, intervalStr, ok := strings.Cut(v, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("trickle %q: want {bytes}/{interval}", v)
+	}
+	size, err := strconv.Atoi(sizeStr)
+	if err != nil || size <= 0 {
+		return 0, 0, fmt.Errorf("trickle %q: bytes must be a positive integer", v)
+	}
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return size, interval, nil
+}
+
+// roll reports whether an event with the given probability happens.
+func roll(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate //nolint:gosec // fault injection does not need crypto randomness
+}
+
+// latency picks the delay for one request.
+func (c faultConfig) latency() time.Duration {
+	if c.LatencyMax <= c.LatencyMin {
+		return c.LatencyMin
+	}
+	return c.LatencyMin + rand.N(c.LatencyMax-c.LatencyMin+1) //nolint:gosec // fault injection does not need crypto randomness
+}
+
+// before injects the faults that happen ahead of the response body: latency,
+// connection resets, and error statuses. It returns false when it has
+// already finished the response and the caller must not write anything else.
+func (c faultConfig) before(w http.ResponseWriter, r *http.Request) bool {
+	if c.LatencyMax > 0 || c.TrickleBytes > 0 {
+		// Slow responses are the point here; don't let WriteTimeout cut them short.
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	}
+
+	if d := c.latency(); d > 0 {
+		log.Printf("fault: delaying %s by %s", r.URL.Path, d)
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return false
+		}
+	}
+
+	if roll(c.ResetRate) {
+		log.Printf("fault: resetting connection for %s", r.URL.Path)
+		resetConnection(w)
+		return false
+	}
+
+	if roll(c.ErrorRate) {
+		log.Printf("fault: responding %d to %s", c.ErrorStatus, r.URL.Path)
+		http.Error(w, http.StatusText(c.ErrorStatus), c.ErrorStatus)
+		return false
+	}
+	return true
+}
+
+// write sends the body, truncating it mid-row or trickling it out slowly
+// when those faults are enabled.
+func (c faultConfig) write(w http.ResponseWriter, r *http.Request, status int, data []byte) error {
+	if roll(c.TruncateRate) {
+		cut := truncationPoint(data)
+		log.Printf("fault: truncating %s at byte %d of %d", r.URL.Path, cut, len(data))
+		// Advertise the full length so the client sees an unexpected EOF
+		// rather than a short but apparently complete file.
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		data = data[:cut]
+	}
+	w.WriteHeader(status)
+
+	if c.TrickleBytes <= 0 {
+		_, err := w.Write(data)
+		return err
+	}
+
+	log.Printf("fault: trickling %s at %d bytes per %s", r.URL.Path, c.TrickleBytes, c.TrickleInterval)
+	rc := http.NewResponseController(w)
+	for len(data) > 0 {
+		n := min(c.TrickleBytes, len(data))
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil {
+			return err
+		}
+		data = data[n:]
+		if len(data) == 0 {
+			break
+		}
+		select {
+		case <-time.After(c.TrickleInterval):
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+	return nil
+}
+
+// truncationPoint picks a byte offset that falls strictly inside a data row,
+// never on a line boundary, so the client receives a partial record: the
+// bytes on both sides of the cut belong to the same row. A file with no data
+// row long enough to split is cut in half.
+func truncationPoint(data []byte) int {
+	start := bytes.IndexByte(data, '\n') + 1 // skip the header row
+	eol := func(b byte) bool { return b == '\n' || b == '\r' }
+	inRow := func(cut int) bool { return !eol(data[cut-1]) && !eol(data[cut]) }
+
+	n := 0
+	for cut := start + 1; start > 0 && cut < len(data); cut++ {
+		if inRow(cut) {
+			n++
+		}
+	}
+	if n == 0 {
+		return len(data) / 2
+	}
+	pick := rand.IntN(n) //nolint:gosec // fault injection does not need crypto randomness
+	for cut := start + 1; ; cut++ {
+		if inRow(cut) {
+			if pick == 0 {
+				return cut
+			}
+			pick--
+		}
+	}
+}
+
+// resetConnection drops the client connection with a TCP RST.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// Hijacking is unsupported (e.g. HTTP/2); abort the stream instead.
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
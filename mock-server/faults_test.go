@@ -0,0 +1,154 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLatency(t *testing.T) {
+	cases := []struct {
+		in      string
+		lo, hi  time.Duration
+		wantErr bool
+	}{
+		{"250ms", 250 * time.Millisecond, 250 * time.Millisecond, false},
+		{"0s", 0, 0, false},
+		{"100ms-2s", 100 * time.Millisecond, 2 * time.Second, false},
+		{"1s-1s", time.Second, time.Second, false},
+		{"2s-100ms", 0, 0, true},
+		{"-1s", 0, 0, true},
+		{"100ms-", 0, 0, true},
+		{"fast", 0, 0, true},
+		{"", 0, 0, true},
+	}
+	for _, tc := range cases {
+		lo, hi, err := parseLatency(tc.in)
+		if (err != nil) != tc.wantErr || lo != tc.lo || hi != tc.hi {
+			t.Errorf("parseLatency(%q) = %s, %s, %v; want %s, %s, error %t", tc.in, lo, hi, err, tc.lo, tc.hi, tc.wantErr)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"0.25", 0.25, false},
+		{"1", 1, false},
+		{"1.01", 0, true},
+		{"-0.1", 0, true},
+		{"half", 0, true},
+		{"", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := parseRate(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseRate(%q) = %v, %v; want %v, error %t", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestParseTrickle(t *testing.T) {
+	cases := []struct {
+		in       string
+		bytes    int
+		interval time.Duration
+		wantErr  bool
+	}{
+		{"64/100ms", 64, 100 * time.Millisecond, false},
+		{"1/1s", 1, time.Second, false},
+		{"0/100ms", 0, 0, true},
+		{"-8/100ms", 0, 0, true},
+		{"64", 0, 0, true},
+		{"64/slow", 0, 0, true},
+		{"many/100ms", 0, 0, true},
+	}
+	for _, tc := range cases {
+		n, interval, err := parseTrickle(tc.in)
+		if (err != nil) != tc.wantErr || n != tc.bytes || interval != tc.interval {
+			t.Errorf("parseTrickle(%q) = %d, %s, %v; want %d, %s, error %t", tc.in, n, interval, err, tc.bytes, tc.interval, tc.wantErr)
+		}
+	}
+}
+
+func TestFaultConfigSet(t *testing.T) {
+	cases := []struct {
+		name, value string
+		wantErr     bool
+		check       func(c faultConfig) bool
+	}{
+		{"error_status", "400", false, func(c faultConfig) bool { return c.ErrorStatus == 400 }},
+		{"error_status", "502", false, func(c faultConfig) bool { return c.ErrorStatus == 502 }},
+		{"error_status", "599", false, func(c faultConfig) bool { return c.ErrorStatus == 599 }},
+		{"error_status", "399", true, nil},
+		{"error_status", "200", true, nil},
+		{"error_status", "600", true, nil},
+		{"error_status", "bad", true, nil},
+		{"latency", "100ms-2s", false, func(c faultConfig) bool {
+			return c.LatencyMin == 100*time.Millisecond && c.LatencyMax == 2*time.Second
+		}},
+		{"error_rate", "0.5", false, func(c faultConfig) bool { return c.ErrorRate == 0.5 }},
+		{"reset_rate", "1", false, func(c faultConfig) bool { return c.ResetRate == 1 }},
+		{"truncate_rate", "0.1", false, func(c faultConfig) bool { return c.TruncateRate == 0.1 }},
+		{"trickle", "64/100ms", false, func(c faultConfig) bool {
+			return c.TrickleBytes == 64 && c.TrickleInterval == 100*time.Millisecond
+		}},
+		{"timeout", "1s", true, nil},
+	}
+	for _, tc := range cases {
+		c := faultConfig{ErrorStatus: http.StatusServiceUnavailable}
+		err := c.set(tc.name, tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("set(%s, %q) error = %v, want error %t", tc.name, tc.value, err, tc.wantErr)
+			continue
+		}
+		if tc.check != nil && !tc.check(c) {
+			t.Errorf("set(%s, %q) = %+v", tc.name, tc.value, c)
+		}
+	}
+}
+
+func TestTruncationPoint(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		// wantHalf is set when no data row can be split, so the body is
+		// cut in half instead.
+		wantHalf bool
+	}{
+		{"header only", "Time,Size\n", true},
+		{"header without newline", "Time,Size", true},
+		{"empty", "", true},
+		{"single row", "Time,Size\n1510,125\n", false},
+		{"single row without trailing newline", "Time,Size\n1510,125", false},
+		{"several rows", "Time,Size\n1510,125\n1703,100\n1704,100\n", false},
+		{"CRLF rows", "Time,Size\r\n1510,125\r\n1703,100\r\n", false},
+		{"one-byte row", "Time,Size\n1\n", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := []byte(tc.data)
+			header := strings.IndexByte(tc.data, '\n') + 1
+			for range 200 {
+				cut := truncationPoint(data)
+				if tc.wantHalf {
+					if cut != len(data)/2 {
+						t.Fatalf("cut = %d, want %d", cut, len(data)/2)
+					}
+					continue
+				}
+				if cut <= header || cut >= len(data) {
+					t.Fatalf("cut = %d is outside the data rows [%d, %d)", cut, header+1, len(data))
+				}
+				if before, after := data[cut-1], data[cut]; strings.ContainsAny(string([]byte{before, after}), "\r\n") {
+					t.Fatalf("cut = %d lands on a line boundary: %q|%q", cut, data[:cut], data[cut:])
+				}
+			}
+		})
+	}
+}
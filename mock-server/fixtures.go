@@ -0,0 +1,396 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reportTypes are the SPC report types the mock server serves.
+var reportTypes = []string{"torn", "hail", "wind"}
+
+// reportNamePattern matches NOAA SPC daily report filenames: {YYMMDD}_rpts_{type}.csv
+var reportNamePattern = regexp.MustCompile(`^(\d{6})_rpts_(torn|hail|wind)\.csv$`)
+
+// reportRequestPattern matches the report URLs SPC publishes: dated
+// {YYMMDD}_rpts_{type}.csv files, the today_{type}.csv and yesterday_{type}.csv
+// aliases, combined files without a type ({YYMMDD}_rpts.csv, today.csv), and
+// a _filtered variant of each.
+var reportRequestPattern = regexp.MustCompile(`^(\d{6}_rpts|today|yesterday)(_filtered)?(?:_(torn|hail|wind))?\.csv$`)
+
+// combinedReport is the fixtureKey type of a combined file holding every
+// report type's section.
+const combinedReport = "all"
+
+// fixtureKey identifies a fixture by its NOAA date prefix and report type.
+type fixtureKey struct {
+	Date string // YYMMDD, e.g. "240426"
+	Type string // torn, hail, or wind
+}
+
+func (k fixtureKey) String() string {
+	if k.Type == combinedReport {
+		return k.Date + "_rpts.csv"
+	}
+	return k.Date + "_rpts_" + k.Type + ".csv"
+}
+
+// fixture is a single CSV report file loaded from DATA_DIR or uploaded
+// through the admin API.
+type fixture struct {
+	key     fixtureKey
+	date    time.Time
+	path    string // empty for uploaded fixtures
+	data    []byte
+	modTime time.Time // file mtime, upload time, or last touch; zero for generated fixtures
+}
+
+// defaultScenario names the fixtures at the top level of DATA_DIR.
+const defaultScenario = "default"
+
+// fixtureStore indexes every *_rpts_*.csv by scenario, date, and type.
+// Files at the top level of DATA_DIR form the default scenario; each
+// subdirectory is a named scenario (e.g. DATA_DIR/quiet-day/).
+//
+// The admin API can replace or remove fixtures at runtime; reset restores
+// the on-disk baseline.
+type fixtureStore struct {
+	dir string
+
+	mu        sync.RWMutex
+	scenarios map[string]map[fixtureKey]*fixture
+}
+
+// parseReportName extracts the date and type from a NOAA report filename.
+// It rejects names whose date prefix is not a valid calendar date.
+func parseReportName(name string) (fixtureKey, bool) {
+	m := reportNamePattern.FindStringSubmatch(name)
+	if m == nil {
+		return fixtureKey{}, false
+	}
+	if _, err := time.Parse("060102", m[1]); err != nil {
+		return fixtureKey{}, false
+	}
+	return fixtureKey{Date: m[1], Type: m[2]}, true
+}
+
+// resolveReport maps a requested report URL name to the fixture that backs
+// it, or to a combinedReport key for combined files. today and yesterday are
+// resolved against now using SPC's convective day, so "today" switches at
+// 12Z. Filtered variants are served from the same fixtures as the
+// unfiltered file.
+func resolveReport(name string, now time.Time) (fixtureKey, bool) {
+	m := reportRequestPattern.FindStringSubmatch(name)
+	if m == nil {
+		return fixtureKey{}, false
+	}
+	today := convectiveDate(now)
+	var date string
+	switch m[1] {
+	case "today":
+		date = today.Format("060102")
+	case "yesterday":
+		date = today.AddDate(0, 0, -1).Format("060102")
+	default:
+		date = strings.TrimSuffix(m[1], "_rpts")
+		if _, err := time.Parse("060102", date); err != nil {
+			return fixtureKey{}, false
+		}
+	}
+	return fixtureKey{Date: date, Type: cmp.Or(m[3], combinedReport)}, true
+}
+
+// convectiveDate returns the date of the convective day (12Z to 12Z)
+// containing t.
+func convectiveDate(t time.Time) time.Time {
+	t = t.UTC().Add(-12 * time.Hour)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// loadFixtures reads every report CSV in dir and its subdirectories into
+// memory. Each fixture's modification time is now, taken from the virtual
+// clock, so Last-Modified never depends on the files' real mtimes.
+func loadFixtures(dir string, now time.Time) (*fixtureStore, error) {
+	scenarios, err := readScenarios(dir, now)
+	if err != nil {
+		return nil, err
+	}
+	return &fixtureStore{dir: dir, scenarios: scenarios}, nil
+}
+
+// readScenarios reads the default scenario from dir and a named scenario
+// from each of its subdirectories.
+func readScenarios(dir string, now time.Time) (map[string]map[fixtureKey]*fixture, error) {
+	scenarios := make(map[string]map[fixtureKey]*fixture)
+
+	files, err := loadScenario(dir, now)
+	if err != nil {
+		return nil, err
+	}
+	scenarios[defaultScenario] = files
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing scenarios: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if e.Name() == defaultScenario || e.Name() == generatedScenario {
+			log.Printf("skipping %s: scenario name %q is reserved", filepath.Join(dir, e.Name()), e.Name())
+			continue
+		}
+		files, err := loadScenario(filepath.Join(dir, e.Name()), now)
+		if err != nil {
+			return nil, err
+		}
+		scenarios[e.Name()] = files
+	}
+	return scenarios, nil
+}
+
+// loadScenario reads the report CSVs directly inside dir, stamping each as
+// modified at now.
+func loadScenario(dir string, now time.Time) (map[fixtureKey]*fixture, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*_rpts_*.csv"))
+	if err != nil {
+		return nil, fmt.Errorf("listing fixtures: %w", err)
+	}
+
+	files := make(map[fixtureKey]*fixture, len(matches))
+	for _, path := range matches {
+		key, ok := parseReportName(filepath.Base(path))
+		if !ok {
+			log.Printf("skipping %s: not a {YYMMDD}_rpts_{type}.csv file", path)
+			continue
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // path comes from a glob of DATA_DIR
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		date, _ := time.Parse("060102", key.Date)
+		files[key] = &fixture{key: key, date: date, path: path, data: data, modTime: now.UTC()}
+	}
+	return files, nil
+}
+
+// lookup returns the fixture for an exact scenario, date, and type.
+func (s *fixtureStore) lookup(scenario string, key fixtureKey) (*fixture, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.scenarios[scenario][key]
+	return f, ok
+}
+
+// hasScenario reports whether a scenario was loaded or created by an upload.
+func (s *fixtureStore) hasScenario(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.scenarios[name]
+	return ok
+}
+
+// count returns the total number of fixtures across all scenarios.
+func (s *fixtureStore) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, files := range s.scenarios {
+		n += len(files)
+	}
+	return n
+}
+
+// put adds or replaces a fixture, creating the scenario if needed.
+func (s *fixtureStore) put(scenario string, f *fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scenarios[scenario] == nil {
+		s.scenarios[scenario] = make(map[fixtureKey]*fixture)
+	}
+	s.scenarios[scenario][f.key] = f
+}
+
+// remove deletes a fixture so requests for it get a 404. It reports
+// whether the fixture existed.
+func (s *fixtureStore) remove(scenario string, key fixtureKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scenarios[scenario][key]; !ok {
+		return false
+	}
+	delete(s.scenarios[scenario], key)
+	return true
+}
+
+// touch sets a fixture's modification time, as if NOAA had republished the
+// file. Fixtures are shared with in-flight requests, so it stores a copy. It
+// reports whether the fixture existed.
+func (s *fixtureStore) touch(scenario string, key fixtureKey, at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.scenarios[scenario][key]
+	if !ok {
+		return false
+	}
+	touched := *f
+	touched.modTime = at
+	s.scenarios[scenario][key] = &touched
+	return true
+}
+
+// reset discards runtime changes and rereads the fixtures from disk, stamping
+// them as modified at now.
+func (s *fixtureStore) reset(now time.Time) error {
+	scenarios, err := readScenarios(s.dir, now)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios = scenarios
+	return nil
+}
+
+// fixtureInfo describes a loaded fixture for the admin API.
+type fixtureInfo struct {
+	Scenario string    `json:"scenario"`
+	File     string    `json:"file"`
+	Date     string    `json:"date"`
+	Type     string    `json:"type"`
+	Bytes    int       `json:"bytes"`
+	Source   string    `json:"source"` // "disk" or "upload"
+	Modified time.Time `json:"modified"`
+}
+
+// list describes every fixture, sorted by scenario and file name.
+func (s *fixtureStore) list() []fixtureInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]fixtureInfo, 0, len(s.scenarios)*len(reportTypes))
+	for scenario, files := range s.scenarios {
+		for key, f := range files {
+			source := "disk"
+			if f.path == "" {
+				source = "upload"
+			}
+			out = append(out, fixtureInfo{
+				Scenario: scenario,
+				File:     key.String(),
+				Date:     key.Date,
+				Type:     key.Type,
+				Bytes:    len(f.data),
+				Source:   source,
+				Modified: f.modTime,
+			})
+		}
+	}
+	slices.SortFunc(out, func(a, b fixtureInfo) int {
+		return cmp.Or(cmp.Compare(a.Scenario, b.Scenario), cmp.Compare(a.File, b.File))
+	})
+	return out
+}
+
+// maxFixtureUpload bounds PUT /__admin/fixtures bodies.
+const maxFixtureUpload = 10 << 20
+
+// adminFixtureTarget resolves the scenario and fixture key addressed by an
+// admin request: /__admin/fixtures/{date}/{type}, with the scenario chosen
+// the same way as for report requests (query or X-Mock-Scenario header).
+// The generated scenario has no stored fixtures, so it cannot be a target.
+func (s *server) adminFixtureTarget(r *http.Request) (string, fixtureKey, error) {
+	key, ok := parseReportName(fixtureKey{Date: r.PathValue("date"), Type: r.PathValue("type")}.String())
+	if !ok {
+		return "", fixtureKey{}, fmt.Errorf("want /__admin/fixtures/{YYMMDD}/{%s}", strings.Join(reportTypes, "|"))
+	}
+	scenario := s.scenarioFor(r)
+	if scenario == generatedScenario {
+		return "", fixtureKey{}, fmt.Errorf("scenario %q is synthesized and has no stored fixtures", generatedScenario)
+	}
+	return scenario, key, nil
+}
+
+// handleListFixtures lists every loaded fixture.
+func (s *server) handleListFixtures(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"fixtures": s.fixtures.list()})
+}
+
+// handlePutFixture stores the request body as the CSV for a date and type.
+func (s *server) handlePutFixture(w http.ResponseWriter, r *http.Request) {
+	scenario, key, err := s.adminFixtureTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFixtureUpload))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	date, _ := time.Parse("060102", key.Date)
+	s.fixtures.put(scenario, &fixture{key: key, date: date, data: data, modTime: s.clock.now()})
+	log.Printf("admin: stored %s/%s (%d bytes)", scenario, key, len(data))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteFixture removes a fixture so requests for it get a 404.
+func (s *server) handleDeleteFixture(w http.ResponseWriter, r *http.Request) {
+	scenario, key, err := s.adminFixtureTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.fixtures.remove(scenario, key) {
+		http.Error(w, fmt.Sprintf("no fixture %s in scenario %s", key, scenario), http.StatusNotFound)
+		return
+	}
+	log.Printf("admin: removed %s/%s", scenario, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTouchFixture bumps a fixture's Last-Modified time so conditional
+// requests see it as changed. ?time= sets an RFC 3339 time instead of the
+// server clock's now.
+func (s *server) handleTouchFixture(w http.ResponseWriter, r *http.Request) {
+	scenario, key, err := s.adminFixtureTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	at := s.clock.now()
+	if v := r.URL.Query().Get("time"); v != "" {
+		if at, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, fmt.Sprintf("time must be RFC 3339: %v", err), http.StatusBadRequest)
+			return
+		}
+		at = at.UTC()
+	}
+	if !s.fixtures.touch(scenario, key, at) {
+		http.Error(w, fmt.Sprintf("no fixture %s in scenario %s", key, scenario), http.StatusNotFound)
+		return
+	}
+	log.Printf("admin: touched %s/%s (modified %s)", scenario, key, at.Format(time.RFC3339))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleResetFixtures restores the on-disk baseline.
+func (s *server) handleResetFixtures(w http.ResponseWriter, _ *http.Request) {
+	if err := s.fixtures.reset(s.clock.now()); err != nil {
+		log.Printf("admin: resetting fixtures: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("admin: reloaded %d fixtures from %s", s.fixtures.count(), s.fixtures.dir)
+	writeJSON(w, http.StatusOK, map[string]any{"fixtures": s.fixtures.list()})
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvectiveDate(t *testing.T) {
+	cases := []struct {
+		now  string
+		want string
+	}{
+		{"2024-04-26T12:00:00Z", "2024-04-26"},
+		{"2024-04-26T23:59:59Z", "2024-04-26"},
+		{"2024-04-27T00:00:00Z", "2024-04-26"},
+		{"2024-04-27T11:59:59Z", "2024-04-26"},
+		{"2024-04-27T12:00:00Z", "2024-04-27"},
+		{"2024-05-01T06:00:00Z", "2024-04-30"},
+		{"2025-01-01T11:00:00Z", "2024-12-31"},
+		{"2024-04-26T08:00:00-05:00", "2024-04-26"}, // 13:00Z
+	}
+	for _, tc := range cases {
+		now, err := time.Parse(time.RFC3339, tc.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := convectiveDate(now).Format("2006-01-02"); got != tc.want {
+			t.Errorf("convectiveDate(%s) = %s, want %s", tc.now, got, tc.want)
+		}
+	}
+}
+
+func TestResolveReport(t *testing.T) {
+	beforeSwitch := time.Date(2024, 4, 27, 11, 59, 0, 0, time.UTC)
+	afterSwitch := time.Date(2024, 4, 27, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		file   string
+		now    time.Time
+		want   fixtureKey
+		wantOK bool
+	}{
+		{"dated type", "240426_rpts_torn.csv", afterSwitch, fixtureKey{"240426", "torn"}, true},
+		{"dated filtered", "240426_rpts_filtered_hail.csv", afterSwitch, fixtureKey{"240426", "hail"}, true},
+		{"dated combined", "240426_rpts.csv", afterSwitch, fixtureKey{"240426", combinedReport}, true},
+		{"dated combined filtered", "240426_rpts_filtered.csv", afterSwitch, fixtureKey{"240426", combinedReport}, true},
+		{"today before 12Z", "today_wind.csv", beforeSwitch, fixtureKey{"240426", "wind"}, true},
+		{"today at 12Z", "today_wind.csv", afterSwitch, fixtureKey{"240427", "wind"}, true},
+		{"yesterday before 12Z", "yesterday_torn.csv", beforeSwitch, fixtureKey{"240425", "torn"}, true},
+		{"yesterday at 12Z", "yesterday_torn.csv", afterSwitch, fixtureKey{"240426", "torn"}, true},
+		{"today combined", "today.csv", afterSwitch, fixtureKey{"240427", combinedReport}, true},
+		{"today filtered", "today_filtered_hail.csv", afterSwitch, fixtureKey{"240427", "hail"}, true},
+		{"invalid date", "241399_rpts_torn.csv", afterSwitch, fixtureKey{}, false},
+		{"unknown type", "240426_rpts_snow.csv", afterSwitch, fixtureKey{}, false},
+		{"not a csv", "240426_rpts_torn.txt", afterSwitch, fixtureKey{}, false},
+		{"short date", "24042_rpts_torn.csv", afterSwitch, fixtureKey{}, false},
+		{"empty", "", afterSwitch, fixtureKey{}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := resolveReport(tc.file, tc.now)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("resolveReport(%q) = %v, %t, want %v, %t", tc.file, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestAdminFixturesRejectGeneratedScenario(t *testing.T) {
+	srv := &server{
+		fixtures:        &fixtureStore{scenarios: map[string]map[fixtureKey]*fixture{defaultScenario: {}}},
+		defaultScenario: defaultScenario,
+		clock:           newClock(),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /__admin/fixtures/{date}/{type}", srv.handlePutFixture)
+	mux.HandleFunc("DELETE /__admin/fixtures/{date}/{type}", srv.handleDeleteFixture)
+	mux.HandleFunc("POST /__admin/fixtures/{date}/{type}/touch", srv.handleTouchFixture)
+
+	cases := []struct {
+		method, target string
+	}{
+		{http.MethodPut, "/__admin/fixtures/240426/hail?scenario=generated"},
+		{http.MethodDelete, "/__admin/fixtures/240426/hail?scenario=generated"},
+		{http.MethodPost, "/__admin/fixtures/240426/hail/touch?scenario=generated"},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, tc.target, strings.NewReader("Time\n1510\n"))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.target, w.Code, http.StatusBadRequest)
+		}
+	}
+	if srv.fixtures.hasScenario(generatedScenario) {
+		t.Error("a generated scenario was stored")
+	}
+}
+
+func TestDiskFixturesUseVirtualClock(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "quiet-day"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"240426_rpts_hail.csv", "quiet-day/240426_rpts_torn.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte("Time\n1510\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clk := newClock()
+	clk.set(time.Date(2024, 4, 26, 18, 0, 0, 0, time.UTC))
+	clk.setFrozen(true)
+	store, err := loadFixtures(dir, clk.now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &server{fixtures: store, defaultScenario: defaultScenario, clock: clk}
+
+	check := func(when string) {
+		t.Helper()
+		infos := store.list()
+		if len(infos) != 2 {
+			t.Fatalf("%s: %d fixtures loaded, want 2", when, len(infos))
+		}
+		for _, info := range infos {
+			if !info.Modified.Equal(clk.now()) {
+				t.Errorf("%s: %s/%s modified %s, want the clock's %s", when, info.Scenario, info.File, info.Modified, clk.now())
+			}
+		}
+	}
+	check("after load")
+
+	clk.advance(time.Hour)
+	w := httptest.NewRecorder()
+	srv.handleResetFixtures(w, httptest.NewRequest(http.MethodPost, "/__admin/fixtures/reset", http.NoBody))
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset = %d: %s", w.Code, w.Body)
+	}
+	check("after reset")
+}
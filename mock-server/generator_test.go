@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"regexp"
+	"testing"
+)
+
+func TestGenerateFixtureDeterministic(t *testing.T) {
+	base := fixtureKey{Date: "240426", Type: "hail"}
+	g := generatorConfig{Seed: 1}
+	want := generateFixture(g, base).data
+
+	if got := generateFixture(g, base).data; !bytes.Equal(got, want) {
+		t.Fatal("same seed and key produced different bytes")
+	}
+
+	cases := []struct {
+		name string
+		g    generatorConfig
+		key  fixtureKey
+	}{
+		{"other date", g, fixtureKey{Date: "240427", Type: "hail"}},
+		{"other type", g, fixtureKey{Date: "240426", Type: "wind"}},
+		{"other seed", generatorConfig{Seed: 2}, base},
+	}
+	for _, tc := range cases {
+		if got := generateFixture(tc.g, tc.key).data; bytes.Equal(got, want) {
+			t.Errorf("%s: produced the same bytes as %s with seed %d", tc.name, base, g.Seed)
+		}
+	}
+}
+
+func TestGenerateFixtureRows(t *testing.T) {
+	hhmm := regexp.MustCompile(`^([01]\d|2[0-3])[0-5]\d$`)
+	office := regexp.MustCompile(`\([A-Z]{3}\)$`)
+
+	cases := []struct {
+		name     string
+		g        generatorConfig
+		key      fixtureKey
+		wantRows int // 0 accepts the seed's plausible count
+	}{
+		{"torn default count", generatorConfig{Seed: 1}, fixtureKey{Date: "240426", Type: "torn"}, 0},
+		{"hail default count", generatorConfig{Seed: 7}, fixtureKey{Date: "250601", Type: "hail"}, 0},
+		{"wind default count", generatorConfig{Seed: 42}, fixtureKey{Date: "240229", Type: "wind"}, 0},
+		{"fixed count", generatorConfig{Seed: 3, Rows: 500}, fixtureKey{Date: "240426", Type: "hail"}, 500},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := generateFixture(tc.g, tc.key)
+			if f.key != tc.key || f.date.Format("060102") != tc.key.Date {
+				t.Errorf("fixture key %v date %s, want %v", f.key, f.date, tc.key)
+			}
+
+			reader := csv.NewReader(bytes.NewReader(f.data))
+			records, err := reader.ReadAll() // fails on rows with the wrong column count
+			if err != nil {
+				t.Fatalf("parsing generated CSV: %v", err)
+			}
+			header := records[0]
+			if got := string(f.data[:bytes.IndexByte(f.data, '\n')]); got != reportHeaders[tc.key.Type] {
+				t.Errorf("header = %q, want %q", got, reportHeaders[tc.key.Type])
+			}
+			rows := records[1:]
+			if tc.wantRows != 0 && len(rows) != tc.wantRows {
+				t.Errorf("got %d rows, want %d", len(rows), tc.wantRows)
+			}
+			if len(rows) == 0 {
+				t.Fatal("no rows generated")
+			}
+			for i, rec := range rows {
+				if len(rec) != len(header) {
+					t.Fatalf("row %d has %d fields, header has %d", i, len(rec), len(header))
+				}
+				if !hhmm.MatchString(rec[0]) {
+					t.Errorf("row %d: Time %q is not HHMM", i, rec[0])
+				}
+				if !office.MatchString(rec[len(rec)-1]) {
+					t.Errorf("row %d: comment %q does not end in an office code", i, rec[len(rec)-1])
+				}
+			}
+		})
+	}
+}
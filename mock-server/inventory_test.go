@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleInventoryScenario(t *testing.T) {
+	date := time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC)
+	store := &fixtureStore{scenarios: map[string]map[fixtureKey]*fixture{}}
+	store.put(defaultScenario, &fixture{
+		key:  fixtureKey{Date: "240426", Type: "hail"},
+		date: date,
+		data: []byte("Time,Size,Location,County,State\n1510,125,Here,Knox,NE\n1600,100,There,Knox,NE\n"),
+	})
+	store.put("quiet-day", &fixture{
+		key:  fixtureKey{Date: "240426", Type: "torn"},
+		date: date,
+		data: []byte("Time,F_Scale,Location,County,State\n1223,UNK,Else,Polk,IA\n"),
+	})
+
+	cases := []struct {
+		name        string
+		defaultName string
+		query       string
+		wantStatus  int
+		wantFiles   int
+		wantRows    int
+		wantStates  map[string]int
+	}{
+		{"no scenario uses the default", defaultScenario, "", http.StatusOK, 1, 2, map[string]int{"NE": 2}},
+		{"no scenario follows DEFAULT_SCENARIO", "quiet-day", "", http.StatusOK, 1, 1, map[string]int{"IA": 1}},
+		{"named scenario", defaultScenario, "?scenario=quiet-day", http.StatusOK, 1, 1, map[string]int{"IA": 1}},
+		{"generated has no fixtures", defaultScenario, "?scenario=generated", http.StatusOK, 0, 0, map[string]int{}},
+		{"generated default has no fixtures", generatedScenario, "", http.StatusOK, 0, 0, map[string]int{}},
+		{"unknown scenario", defaultScenario, "?scenario=nope", http.StatusNotFound, 0, 0, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &server{fixtures: store, defaultScenario: tc.defaultName}
+			w := httptest.NewRecorder()
+			srv.handleInventory(w, httptest.NewRequest(http.MethodGet, "/fixtures"+tc.query, http.NoBody))
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tc.wantStatus)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Fixtures []fixtureInventory `json:"fixtures"`
+				Totals   inventoryTotals    `json:"totals"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Fixtures) != tc.wantFiles {
+				t.Errorf("%d fixtures listed, want %d: %+v", len(got.Fixtures), tc.wantFiles, got.Fixtures)
+			}
+			if got.Totals.Rows != tc.wantRows {
+				t.Errorf("totals.rows = %d, want %d", got.Totals.Rows, tc.wantRows)
+			}
+			if len(got.Totals.ByState) != len(tc.wantStates) {
+				t.Errorf("totals.byState = %v, want %v", got.Totals.ByState, tc.wantStates)
+			}
+			for state, n := range tc.wantStates {
+				if got.Totals.ByState[state] != n {
+					t.Errorf("totals.byState[%s] = %d, want %d", state, got.Totals.ByState[state], n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// journalEntry records one request the mock server handled.
+type journalEntry struct {
+	Seq        uint64            `json:"seq"`
+	Time       time.Time         `json:"time"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Query      string            `json:"query,omitempty"`
+	Headers    map[string]string `json:"headers"`
+	Date       string            `json:"date,omitempty"` // YYMMDD of a report request, with aliases resolved
+	Type       string            `json:"type,omitempty"` // torn, hail, or wind
+	Status     int               `json:"status"`         // 0 when the connection was reset
+	Bytes      int64             `json:"bytes"`
+	DurationMs float64           `json:"durationMs"`
+	Reset      bool              `json:"reset,omitempty"`
+}
+
+// journal is an in-memory ring buffer of recent requests, so e2e tests can
+// assert what the collector actually fetched.
+type journal struct {
+	mu      sync.Mutex
+	entries []journalEntry // ring buffer, oldest at start once full
+	start   int
+	seq     uint64
+	changed chan struct{} // closed and replaced on every append
+}
+
+func newJournal(size int) *journal {
+	return &journal{entries: make([]journalEntry, 0, size), changed: make(chan struct{})}
+}
+
+func (j *journal) append(e journalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.seq++
+	e.Seq = j.seq
+	if len(j.entries) < cap(j.entries) {
+		j.entries = append(j.entries, e)
+	} else {
+		j.entries[j.start] = e
+		j.start = (j.start + 1) % len(j.entries)
+	}
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *journal) clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = j.entries[:0]
+	j.start = 0
+}
+
+// journalFilter selects entries; empty fields match anything.
+type journalFilter struct {
+	Type   string
+	Date   string
+	Method string
+	Path   string // path.Match glob
+	Since  uint64 // only entries with a greater Seq
+}
+
+func (f journalFilter) match(e *journalEntry) bool {
+	if f.Type != "" && e.Type != f.Type {
+		return false
+	}
+	if f.Date != "" && e.Date != f.Date {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(e.Method, f.Method) {
+		return false
+	}
+	if f.Path != "" {
+		if ok, _ := path.Match(f.Path, e.Path); !ok {
+			return false
+		}
+	}
+	return e.Seq > f.Since
+}
+
+// find returns matching entries oldest first, plus the channel that will be
+// closed on the next append.
+func (j *journal) find(f journalFilter) ([]journalEntry, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var out []journalEntry
+	for i := range j.entries {
+		e := &j.entries[(j.start+i)%len(j.entries)]
+		if f.match(e) {
+			out = append(out, *e)
+		}
+	}
+	return out, j.changed
+}
+
+// wait blocks until at least n entries match or ctx ends, then returns the
+// matches found so far.
+func (j *journal) wait(ctx context.Context, f journalFilter, n int) []journalEntry {
+	for {
+		out, changed := j.find(f)
+		if len(out) >= n {
+			return out
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return out
+		}
+	}
+}
+
+// middleware records every request except health checks and admin calls.
+// reportKey resolves a request's report file name so entries carry its date and type.
+func (j *journal) middleware(next http.Handler, reportKey func(r *http.Request) (fixtureKey, bool)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" || strings.HasPrefix(r.URL.Path, "/__admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		jw := &journalWriter{ResponseWriter: w}
+		e := journalEntry{
+			Time:    start.UTC(),
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Query:   r.URL.RawQuery,
+			Headers: flattenHeaders(r.Header),
+		}
+		if key, ok := reportKey(r); ok {
+			e.Date, e.Type = key.Date, key.Type
+		}
+		// Deferred so aborted handlers (panic(http.ErrAbortHandler)) are still recorded.
+		defer func() {
+			e.Status, e.Bytes, e.Reset = jw.status, jw.bytes, jw.hijacked
+			if e.Status == 0 && !e.Reset {
+				e.Status = http.StatusOK
+			}
+			e.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+			j.append(e)
+		}()
+		next.ServeHTTP(jw, r)
+	})
+}
+
+func flattenHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// journalWriter captures the status and size of a response.
+type journalWriter struct {
+	http.ResponseWriter
+	status   int
+	bytes    int64
+	hijacked bool
+}
+
+func (w *journalWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *journalWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Hijack marks the request as reset; http.ResponseController finds it here
+// before unwrapping.
+func (w *journalWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *journalWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// maxJournalWait bounds long-poll requests to /__admin/requests.
+const maxJournalWait = 5 * time.Minute
+
+// handleGetRequests lists journaled requests. Query parameters filter the
+// list (type, date, method, path, since); min=N long-polls until at least N
+// requests match or timeout (default 30s) elapses.
+func (s *server) handleGetRequests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := journalFilter{
+		Type:   q.Get("type"),
+		Date:   q.Get("date"),
+		Method: q.Get("method"),
+		Path:   q.Get("path"),
+	}
+	var err error
+	if v := q.Get("since"); v != "" {
+		if f.Since, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, fmt.Sprintf("since: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	minCount := 0
+	if v := q.Get("min"); v != "" {
+		if minCount, err = strconv.Atoi(v); err != nil || minCount < 0 {
+			http.Error(w, "min must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	timeout := 30 * time.Second
+	if v := q.Get("timeout"); v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil || timeout < 0 || timeout > maxJournalWait {
+			http.Error(w, fmt.Sprintf("timeout must be a duration up to %s", maxJournalWait), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var entries []journalEntry
+	if minCount > 0 {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		entries = s.journal.wait(ctx, f, minCount)
+	} else {
+		entries, _ = s.journal.find(f)
+	}
+	if entries == nil {
+		entries = []journalEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(entries), "requests": entries})
+}
+
+// handleClearRequests empties the journal. Sequence numbers keep increasing.
+func (s *server) handleClearRequests(w http.ResponseWriter, _ *http.Request) {
+	s.journal.clear()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func seqs(entries []journalEntry) []uint64 {
+	out := make([]uint64, len(entries))
+	for i, e := range entries {
+		out[i] = e.Seq
+	}
+	return out
+}
+
+func TestJournalFind(t *testing.T) {
+	types := []string{"torn", "hail", "wind", "hail", "torn"}
+
+	cases := []struct {
+		name    string
+		appends int // entries appended to a journal of size 3, typed per types
+		clear   int // clear after this many appends; 0 never clears
+		filter  journalFilter
+		want    []uint64
+	}{
+		{"not yet full", 2, 0, journalFilter{}, []uint64{1, 2}},
+		{"exactly full", 3, 0, journalFilter{}, []uint64{1, 2, 3}},
+		{"wrapped once", 4, 0, journalFilter{}, []uint64{2, 3, 4}},
+		{"wrapped twice", 5, 0, journalFilter{}, []uint64{3, 4, 5}},
+		{"wrapped and filtered by type", 5, 0, journalFilter{Type: "hail"}, []uint64{4}},
+		{"since", 5, 0, journalFilter{Since: 3}, []uint64{4, 5}},
+		{"since beyond the newest", 5, 0, journalFilter{Since: 5}, nil},
+		{"cleared", 4, 4, journalFilter{}, nil},
+		{"appended after clear", 5, 4, journalFilter{}, []uint64{5}},
+		{"since before clear", 5, 2, journalFilter{Since: 1}, []uint64{3, 4, 5}},
+		{"since after clear", 5, 4, journalFilter{Since: 4}, []uint64{5}},
+		{"since after a cleared journal", 4, 4, journalFilter{Since: 2}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			j := newJournal(3)
+			for i := range tc.appends {
+				j.append(journalEntry{Type: types[i]})
+				if i+1 == tc.clear {
+					j.clear()
+				}
+			}
+			got, _ := j.find(tc.filter)
+			if !slices.Equal(seqs(got), tc.want) {
+				t.Errorf("find = %v, want %v", seqs(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestJournalWait(t *testing.T) {
+	t.Run("returns when an append arrives", func(t *testing.T) {
+		j := newJournal(3)
+		j.append(journalEntry{Type: "hail"})
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			j.append(journalEntry{Type: "torn"})
+			j.append(journalEntry{Type: "hail"})
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		got := j.wait(ctx, journalFilter{Type: "hail"}, 2)
+		if !slices.Equal(seqs(got), []uint64{1, 3}) {
+			t.Errorf("wait = %v, want [1 3]", seqs(got))
+		}
+		if ctx.Err() != nil {
+			t.Error("wait returned only once the context ended")
+		}
+	})
+
+	t.Run("returns what it has when ctx ends", func(t *testing.T) {
+		j := newJournal(3)
+		j.append(journalEntry{Type: "hail"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		got := j.wait(ctx, journalFilter{Type: "hail"}, 2)
+		if !slices.Equal(seqs(got), []uint64{1}) {
+			t.Errorf("wait = %v, want [1]", seqs(got))
+		}
+		if ctx.Err() == nil {
+			t.Error("wait returned before the context ended")
+		}
+	})
+
+	t.Run("already satisfied", func(t *testing.T) {
+		j := newJournal(3)
+		j.append(journalEntry{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if got := j.wait(ctx, journalFilter{}, 1); len(got) != 1 {
+			t.Errorf("wait = %v, want one entry", seqs(got))
+		}
+	})
+}
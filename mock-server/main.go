@@ -6,20 +6,51 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 func main() {
-	dataDir := os.Getenv("DATA_DIR")
-	if dataDir == "" {
-		dataDir = "/data"
+	cfg, err := configFromEnv()
+	if err != nil {
+		log.Fatal(err)
 	}
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+
+	clk := newClock()
+	if !cfg.ClockStart.IsZero() {
+		clk.set(cfg.ClockStart)
+	}
+	clk.setFrozen(cfg.ClockFrozen)
+
+	script, err := loadScript(cfg.ScriptFile)
+	if err != nil {
+		log.Fatalf("loading SCRIPT_FILE: %v", err)
+	}
+
+	fixtures, err := loadFixtures(cfg.DataDir, clk.now())
+	if err != nil {
+		log.Fatalf("loading fixtures: %v", err)
+	}
+	if cfg.Scenario != generatedScenario && !fixtures.hasScenario(cfg.Scenario) {
+		log.Fatalf("DEFAULT_SCENARIO %q not found in %s", cfg.Scenario, cfg.DataDir)
+	}
+	log.Printf("loaded %d fixtures in %d scenarios from %s", fixtures.count(), len(fixtures.scenarios), cfg.DataDir)
+
+	srv := &server{
+		fixtures:        fixtures,
+		defaultScenario: cfg.Scenario,
+		fallbackDate:    cfg.FallbackDate,
+		serveMode:       cfg.ServeMode,
+		convectiveDay:   cfg.ConvectiveDay,
+		faults:          cfg.Faults,
+		script:          script,
+		journal:         newJournal(cfg.JournalSize),
+		generator:       cfg.Generator,
+		revealMode:      cfg.Reveal,
+		revealer:        newRevealer(),
+		clock:           clk,
 	}
 
 	mux := http.NewServeMux()
@@ -29,120 +60,106 @@ func main() {
 		fmt.Fprintln(w, `{"status":"healthy"}`)
 	})
 
-	// Match NOAA URL pattern: /{YYMMDD}_rpts_{type}.csv
-	// Serve the appropriate fixture regardless of date prefix.
-	// The Time column is expanded from HHMM to full ISO 8601 using the
-	// fixture's date so the collector produces correct historical timestamps.
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		name := strings.TrimPrefix(r.URL.Path, "/")
-
-		var csvType string
-		switch {
-		case strings.HasSuffix(name, "_rpts_torn.csv"):
-			csvType = "torn"
-		case strings.HasSuffix(name, "_rpts_hail.csv"):
-			csvType = "hail"
-		case strings.HasSuffix(name, "_rpts_wind.csv"):
-			csvType = "wind"
-		default:
-			http.NotFound(w, r)
-			return
-		}
-
-		matches, _ := filepath.Glob(filepath.Join(dataDir, "*_rpts_"+csvType+".csv"))
-		if len(matches) == 0 {
-			log.Printf("no fixture found for type %s", csvType)
-			http.Error(w, "fixture not found", http.StatusNotFound)
-			return
-		}
-
-		data, err := os.ReadFile(matches[0])
-		if err != nil {
-			log.Printf("error reading %s: %v", matches[0], err)
-			http.Error(w, "fixture not found", http.StatusInternalServerError)
-			return
-		}
-
-		// Extract YYMMDD date from fixture filename (e.g. "240426_rpts_hail.csv")
-		base := filepath.Base(matches[0])
-		var fixtureDate time.Time
-		if len(base) >= 6 {
-			fixtureDate, _ = time.Parse("060102", base[:6])
-		}
-
-		// Expand HHMM times to ISO 8601 if we have a valid fixture date
-		if !fixtureDate.IsZero() {
-			data = expandTimes(data, fixtureDate)
-		}
-
-		log.Printf("serving %s for request %s", filepath.Base(matches[0]), r.URL.Path)
-		w.Header().Set("Content-Type", "text/csv")
-		if _, err := w.Write(data); err != nil {
-			log.Printf("error writing response: %v", err)
-		}
-	})
-
-	addr := ":" + port
-	srv := &http.Server{
+	mux.HandleFunc("GET /fixtures", srv.handleInventory)
+	mux.HandleFunc("GET /__admin/fixtures", srv.handleListFixtures)
+	mux.HandleFunc("PUT /__admin/fixtures/{date}/{type}", srv.handlePutFixture)
+	mux.HandleFunc("DELETE /__admin/fixtures/{date}/{type}", srv.handleDeleteFixture)
+	mux.HandleFunc("POST /__admin/fixtures/{date}/{type}/touch", srv.handleTouchFixture)
+	mux.HandleFunc("POST /__admin/fixtures/reset", srv.handleResetFixtures)
+	mux.HandleFunc("GET /__admin/requests", srv.handleGetRequests)
+	mux.HandleFunc("DELETE /__admin/requests", srv.handleClearRequests)
+	mux.HandleFunc("GET /__admin/script", srv.handleGetScript)
+	mux.HandleFunc("PUT /__admin/script", srv.handlePutScript)
+	mux.HandleFunc("POST /__admin/script/reset", srv.handleResetScript)
+	mux.HandleFunc("GET /__admin/reveal", srv.handleGetReveal)
+	mux.HandleFunc("GET /__admin/clock", srv.handleGetClock)
+	mux.HandleFunc("PUT /__admin/clock", srv.handlePutClock)
+	mux.HandleFunc("POST /__admin/clock/advance", srv.handleAdvanceClock)
+	mux.HandleFunc("POST /__admin/clock/reset", srv.handleResetClock)
+	mux.HandleFunc("POST /__admin/reveal/reset", srv.handleResetReveal)
+
+	// Match NOAA URL patterns: /{YYMMDD}_rpts_{type}.csv, /today_{type}.csv,
+	// /yesterday_{type}.csv and their _filtered variants, optionally under
+	// a /scenarios/{name}/ prefix to select a fixture set per request.
+	mux.HandleFunc("GET /scenarios/{scenario}/{file}", srv.handleReport)
+	mux.HandleFunc("/", srv.handleReport)
+
+	addr := ":" + cfg.Port
+	httpServer := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      srv.journal.middleware(mux, srv.reportKey),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
-	log.Printf("mock-server listening on %s (data_dir=%s)", addr, dataDir)
-	log.Fatal(srv.ListenAndServe())
+	log.Printf("mock-server listening on %s (data_dir=%s, serve_mode=%s)", addr, cfg.DataDir, cfg.ServeMode)
+	log.Fatal(httpServer.ListenAndServe())
 }
 
 // expandTimes rewrites the Time column from HHMM to ISO 8601 using the given date.
 // E.g. "1510" + 2024-04-26 → "2024-04-26T15:10:00Z"
-func expandTimes(data []byte, date time.Time) []byte {
-	reader := csv.NewReader(bytes.NewReader(data))
-	records, err := reader.ReadAll()
-	if err != nil || len(records) < 2 {
+//
+// SPC daily files cover the convective day, 12Z to 12Z, so when convectiveDay
+// is set rows timed 0000–1159 are stamped with the following date:
+// "0012" + 2024-04-26 → "2024-04-27T00:12:00Z".
+//
+// Rows are rewritten one at a time, so a malformed row is served verbatim
+// among the expanded ones rather than leaving the whole file unexpanded.
+func expandTimes(data []byte, date time.Time, convectiveDay bool) []byte {
+	header, rows := splitRows(data)
+	cols, err := csv.NewReader(bytes.NewReader(header)).Read()
+	timeIdx := slices.Index(cols, "Time")
+	if err != nil || timeIdx < 0 || len(rows) == 0 {
 		return data
 	}
 
-	// Find the Time column index
-	header := records[0]
-	timeIdx := -1
-	for i, col := range header {
-		if col == "Time" {
-			timeIdx = i
-			break
-		}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)+len(rows)*12))
+	out.Write(header)
+	for _, row := range rows {
+		out.Write(expandRowTime(row, timeIdx, date, convectiveDay))
 	}
-	if timeIdx < 0 {
-		return data
-	}
-
-	dateStr := date.Format("2006-01-02")
+	return out.Bytes()
+}
 
-	for i := 1; i < len(records); i++ {
-		if timeIdx >= len(records[i]) {
-			continue
-		}
-		hhmm := strings.TrimSpace(records[i][timeIdx])
-		records[i][timeIdx] = expandHHMM(hhmm, dateStr)
+// expandRowTime rewrites one row's Time field in place, leaving every other
+// byte of the row as it was. A row that does not parse as a CSV record (an
+// unterminated quote, a blank line), has no Time field, quotes it, or holds
+// something other than an HHMM time there is returned unchanged; rows with
+// more or fewer fields than the header are still expanded.
+func expandRowTime(row []byte, timeIdx int, date time.Time, convectiveDay bool) []byte {
+	reader := csv.NewReader(bytes.NewReader(row))
+	reader.FieldsPerRecord = -1
+	rec, err := reader.Read()
+	if err != nil || timeIdx >= len(rec) {
+		return row
 	}
-
-	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
-	_ = writer.WriteAll(records)
-	writer.Flush()
-	return buf.Bytes()
+	_, col := reader.FieldPos(timeIdx)
+	start, raw := col-1, rec[timeIdx]
+	if !bytes.HasPrefix(row[start:], []byte(raw)) {
+		return row // quoted field
+	}
+	at, ok := hhmmTime(strings.TrimSpace(raw), date, convectiveDay)
+	if !ok {
+		return row
+	}
+	return slices.Concat(row[:start], []byte(at.Format(time.RFC3339)), row[start+len(raw):])
 }
 
-// expandHHMM converts an HHMM string to ISO 8601 with the given date prefix.
-func expandHHMM(hhmm, dateStr string) string {
-	if len(hhmm) < 3 {
-		return dateStr + "T00:00:00Z"
+// hhmmTime reads a 3- or 4-digit HHMM time of day on date. With
+// convectiveDay set, 0000–1159 falls on the following date, the part of the
+// convective day past midnight. Anything else, including an empty time or
+// one past 2359, is not a time and is reported as such rather than guessed.
+func hhmmTime(hhmm string, date time.Time, convectiveDay bool) (time.Time, bool) {
+	if len(hhmm) < 3 || len(hhmm) > 4 || strings.Trim(hhmm, "0123456789") != "" {
+		return time.Time{}, false
+	}
+	n, _ := strconv.Atoi(hhmm)
+	hour, minute := n/100, n%100
+	if hour > 23 || minute > 59 {
+		return time.Time{}, false
 	}
-	padded := hhmm
-	for len(padded) < 4 {
-		padded = "0" + padded
+	if convectiveDay && hour < 12 {
+		date = date.AddDate(0, 0, 1)
 	}
-	hours := padded[:2]
-	mins := padded[2:4]
-	return dateStr + "T" + hours + ":" + mins + ":00Z"
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, time.UTC), true
 }
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHHMMTime(t *testing.T) {
+	date := time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		hhmm          string
+		convectiveDay bool
+		want          string // "" when hhmm is not a time
+	}{
+		{"0000", true, "2024-04-27T00:00:00Z"},
+		{"0012", true, "2024-04-27T00:12:00Z"},
+		{"1159", true, "2024-04-27T11:59:00Z"},
+		{"1200", true, "2024-04-26T12:00:00Z"},
+		{"1510", true, "2024-04-26T15:10:00Z"},
+		{"2359", true, "2024-04-26T23:59:00Z"},
+		{"945", true, "2024-04-27T09:45:00Z"}, // 3-digit 09:45
+		{"000", true, "2024-04-27T00:00:00Z"}, // 3-digit 00:00
+		{"0012", false, "2024-04-26T00:12:00Z"},
+		{"945", false, "2024-04-26T09:45:00Z"},
+		{"", true, ""},
+		{"45", true, ""}, // too short
+		{"12345", true, ""},
+		{"2400", true, ""},
+		{"2561", true, ""},
+		{"1260", true, ""},
+		{"17O9", true, ""},
+		{"+945", true, ""},
+		{"-945", true, ""},
+		{"UNK", true, ""},
+	}
+	for _, tc := range cases {
+		at, ok := hhmmTime(tc.hhmm, date, tc.convectiveDay)
+		got := ""
+		if ok {
+			got = at.Format(time.RFC3339)
+		}
+		if got != tc.want {
+			t.Errorf("hhmmTime(%q, convectiveDay=%t) = %q, want %q", tc.hhmm, tc.convectiveDay, got, tc.want)
+		}
+	}
+}
+
+func TestExpandTimes(t *testing.T) {
+	date := time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC)
+	const header = "Time,F_Scale,Location\n"
+
+	cases := []struct {
+		name          string
+		in            string
+		convectiveDay bool
+		want          string
+	}{
+		{
+			name:          "afternoon stays on the file date",
+			in:            header + "1510,EF1,Here\n",
+			convectiveDay: true,
+			want:          header + "2024-04-26T15:10:00Z,EF1,Here\n",
+		},
+		{
+			name:          "1200 starts the convective day",
+			in:            header + "1200,EF1,Here\n",
+			convectiveDay: true,
+			want:          header + "2024-04-26T12:00:00Z,EF1,Here\n",
+		},
+		{
+			name:          "0000 and 1159 roll to the next date",
+			in:            header + "0000,EF1,Here\n1159,EF0,There\n",
+			convectiveDay: true,
+			want:          header + "2024-04-27T00:00:00Z,EF1,Here\n2024-04-27T11:59:00Z,EF0,There\n",
+		},
+		{
+			name:          "3-digit time is padded and rolled",
+			in:            header + "945,EF1,Here\n",
+			convectiveDay: true,
+			want:          header + "2024-04-27T09:45:00Z,EF1,Here\n",
+		},
+		{
+			name:          "calendar day keeps early times on the file date",
+			in:            header + "0012,EF1,Here\n",
+			convectiveDay: false,
+			want:          header + "2024-04-26T00:12:00Z,EF1,Here\n",
+		},
+		{
+			name:          "empty or invalid time is left unchanged",
+			in:            header + ",EF1,Here\n2561,EF0,There\n",
+			convectiveDay: true,
+			want:          header + ",EF1,Here\n2561,EF0,There\n",
+		},
+		{
+			name:          "header only is unchanged",
+			in:            header,
+			convectiveDay: true,
+			want:          header,
+		},
+		{
+			name:          "no Time column is unchanged",
+			in:            "Speed,Location\n65,Here\n",
+			convectiveDay: true,
+			want:          "Speed,Location\n65,Here\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := string(expandTimes([]byte(tc.in), date, tc.convectiveDay))
+			if got != tc.want {
+				t.Errorf("expandTimes:\ngot  %q\nwant %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandTimesMonthEnd(t *testing.T) {
+	date := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	got := string(expandTimes([]byte("Time\n0100\n"), date, true))
+	if want := "Time\n2024-05-01T01:00:00Z\n"; got != want {
+		t.Errorf("expandTimes = %q, want %q", got, want)
+	}
+}
+
+// TestExpandTimesMalformedRows checks that the malformed-rows scenario is
+// expanded row by row: each row's Time field is rewritten where it can be,
+// and every other byte, including whole unparseable rows, is served as is.
+func TestExpandTimesMalformedRows(t *testing.T) {
+	date := time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		file string
+		// wantTimes maps a data row index to its expected Time field.
+		wantTimes map[int]string
+	}{
+		{"240426_rpts_hail.csv", map[int]string{
+			0: "2024-04-26T15:10:00Z",
+			1: "2024-04-26T17:03:00Z", // short row
+			2: "2024-04-26T17:04:00Z", // extra fields
+			3: "17O9",                 // letter O: not a time
+			4: "2024-04-26T17:12:00Z",
+			5: "1715", // unterminated quote
+		}},
+		{"240426_rpts_torn.csv", map[int]string{
+			0: "2024-04-26T12:23:00Z",
+			1: "", // missing time stays missing
+			2: "", // blank line
+			3: "2024-04-26T17:26:00Z",
+		}},
+		{"240426_rpts_wind.csv", map[int]string{
+			0: "2024-04-26T12:45:00Z",
+			1: "2024-04-26T12:51:00Z",
+			2: "2561", // past 2359: not a time
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			in, err := os.ReadFile(filepath.Join("data", "malformed-rows", tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			inHeader, inRows := splitRows(in)
+			outHeader, outRows := splitRows(expandTimes(in, date, true))
+			if string(outHeader) != string(inHeader) {
+				t.Errorf("header = %q, want %q", outHeader, inHeader)
+			}
+			if len(outRows) != len(inRows) {
+				t.Fatalf("got %d rows, want %d", len(outRows), len(inRows))
+			}
+			for i := range inRows {
+				_, inRest, _ := strings.Cut(string(inRows[i]), ",")
+				gotTime, gotRest, _ := strings.Cut(strings.TrimSuffix(string(outRows[i]), "\n"), ",")
+				inRest = strings.TrimSuffix(inRest, "\n")
+				if gotRest != inRest {
+					t.Errorf("row %d: expansion changed more than Time:\ngot  %q\nwant %q", i, outRows[i], inRows[i])
+				}
+				if want, ok := tc.wantTimes[i]; ok && gotTime != want {
+					t.Errorf("row %d: Time = %q, want %q", i, gotTime, want)
+				}
+			}
+		})
+	}
+}
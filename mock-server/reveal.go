@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reveal modes make a report file grow the way SPC's current-day files do
+// as reports trickle in.
+const (
+	// revealOff serves every row.
+	revealOff = "off"
+	// revealClock serves the rows whose event time has passed.
+	revealClock = "clock"
+	// revealFetch serves Rows more rows on each fetch of a file.
+	revealFetch = "fetch"
+)
+
+// revealConfig selects a reveal mode. Rows is the step size for revealFetch.
+type revealConfig struct {
+	Mode string
+	Rows int
+}
+
+func (c revealConfig) String() string {
+	if c.Mode == revealFetch {
+		return fmt.Sprintf("%s:%d", c.Mode, c.Rows)
+	}
+	return c.Mode
+}
+
+// parseReveal parses "off", "clock", or "fetch:N".
+func parseReveal(v string) (revealConfig, error) {
+	switch mode, n, _ := strings.Cut(v, ":"); mode {
+	case revealOff, revealClock:
+		if n != "" {
+			return revealConfig{}, fmt.Errorf("reveal %q: %s takes no argument", v, mode)
+		}
+		return revealConfig{Mode: mode}, nil
+	case revealFetch:
+		rows, err := strconv.Atoi(n)
+		if err != nil || rows < 1 {
+			return revealConfig{}, fmt.Errorf("reveal %q: want fetch:N with N ≥ 1", v)
+		}
+		return revealConfig{Mode: mode, Rows: rows}, nil
+	default:
+		return revealConfig{}, fmt.Errorf("reveal %q: want %s, %s, or %s:N", v, revealOff, revealClock, revealFetch)
+	}
+}
+
+// revealer counts fetches per file for revealFetch and remembers when each
+// file last grew, which becomes its Last-Modified time.
+type revealer struct {
+	mu    sync.Mutex
+	files map[string]*revealState // keyed by revealName
+}
+
+// revealState is the progress of one file under revealFetch.
+type revealState struct {
+	Fetches  int       `json:"fetches"`
+	Revealed int       `json:"revealed"`
+	Rows     int       `json:"rows"`
+	Changed  time.Time `json:"changed"`
+}
+
+func newRevealer() *revealer {
+	return &revealer{files: make(map[string]*revealState)}
+}
+
+// fetch records a fetch of a file with total rows and returns how many rows
+// it reveals and when that count last changed.
+func (rv *revealer) fetch(name string, step, total int, now time.Time) (int, time.Time) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	st := rv.files[name]
+	if st == nil {
+		st = &revealState{}
+		rv.files[name] = st
+	}
+	st.Fetches++
+	st.Rows = total
+	if n := min(st.Fetches*step, total); n != st.Revealed {
+		st.Revealed = n
+		st.Changed = now
+	}
+	return st.Revealed, st.Changed
+}
+
+func (rv *revealer) reset() {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	clear(rv.files)
+}
+
+func (rv *revealer) state() map[string]revealState {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	out := make(map[string]revealState, len(rv.files))
+	for name, st := range rv.files {
+		out[name] = *st
+	}
+	return out
+}
+
+// reveal trims a fixture to the rows visible under the server's reveal mode
+// or the request's reveal override, reading row times per convectiveDay.
+// Fetch progress is counted under name, the requested file. It returns f
+// unchanged when every row is visible.
+func (s *server) reveal(r *http.Request, name string, f *fixture, convectiveDay bool) (*fixture, error) {
+	cfg := s.revealMode
+	if v := requestOption(r, "reveal"); v != "" {
+		var err error
+		if cfg, err = parseReveal(v); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Mode == revealOff {
+		return f, nil
+	}
+
+	header, rows := splitRows(f.data)
+	var (
+		visible = rows
+		changed = f.modTime
+	)
+	switch cfg.Mode {
+	case revealClock:
+		now, err := s.nowFor(r)
+		if err != nil {
+			return nil, err
+		}
+		visible, changed = rowsBefore(header, rows, f.date, convectiveDay, now)
+	case revealFetch:
+		var n int
+		n, changed = s.revealer.fetch(name, cfg.Rows, len(rows), s.clock.now())
+		visible = rows[:n]
+	}
+	if len(visible) == len(rows) {
+		return f, nil
+	}
+
+	log.Printf("reveal %s: %d of %d rows of %s", cfg.Mode, len(visible), len(rows), name)
+	partial := *f
+	partial.data = slices.Concat(header, bytes.Join(visible, nil))
+	partial.modTime = changed
+	return &partial, nil
+}
+
+// splitRows splits a CSV into its header line and data lines, each keeping
+// its line ending so they can be rejoined byte-for-byte. NOAA report files
+// have no quoted newlines.
+func splitRows(data []byte) ([]byte, [][]byte) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if last := len(lines) - 1; last >= 0 && len(lines[last]) == 0 {
+		lines = lines[:last]
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	return lines[0], lines[1:]
+}
+
+// rowsBefore returns the rows whose event time is at or before now and the
+// latest such time. Rows whose time cannot be read are always visible.
+func rowsBefore(header []byte, rows [][]byte, date time.Time, convectiveDay bool, now time.Time) ([][]byte, time.Time) {
+	cols, err := csv.NewReader(bytes.NewReader(header)).Read()
+	timeIdx := slices.Index(cols, "Time")
+	if err != nil || timeIdx < 0 {
+		return rows, time.Time{}
+	}
+
+	var (
+		visible [][]byte
+		latest  time.Time
+	)
+	for _, row := range rows {
+		at, ok := rowTime(row, timeIdx, date, convectiveDay)
+		if !ok {
+			visible = append(visible, row)
+			continue
+		}
+		if !at.After(now) {
+			visible = append(visible, row)
+			if at.After(latest) {
+				latest = at
+			}
+		}
+	}
+	return visible, latest
+}
+
+// rowTime reads a row's HHMM time as an instant, applying the convective-day
+// rollover the same way expandTimes does.
+func rowTime(row []byte, timeIdx int, date time.Time, convectiveDay bool) (time.Time, bool) {
+	rec, err := csv.NewReader(bytes.NewReader(row)).Read()
+	if err != nil || timeIdx >= len(rec) {
+		return time.Time{}, false
+	}
+	return hhmmTime(strings.TrimSpace(rec[timeIdx]), date, convectiveDay)
+}
+
+// nowFor returns the current time for a request: the now option (an RFC 3339
+// time in ?now= or X-Mock-Now) if given, otherwise the server's clock.
+func (s *server) nowFor(r *http.Request) (time.Time, error) {
+	v := requestOption(r, "now")
+	if v == "" {
+		return s.clock.now(), nil
+	}
+	now, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.New("now must be an RFC 3339 time")
+	}
+	return now.UTC(), nil
+}
+
+// handleGetReveal reports fetch-mode progress per file.
+func (s *server) handleGetReveal(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"mode":  s.revealMode.String(),
+		"files": s.revealer.state(),
+	})
+}
+
+// handleResetReveal restarts fetch-mode reveals from the first step.
+func (s *server) handleResetReveal(w http.ResponseWriter, _ *http.Request) {
+	s.revealer.reset()
+	log.Printf("admin: reveal counters reset")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseReveal(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    revealConfig
+		wantErr bool
+	}{
+		{"off", revealConfig{Mode: revealOff}, false},
+		{"clock", revealConfig{Mode: revealClock}, false},
+		{"fetch:5", revealConfig{Mode: revealFetch, Rows: 5}, false},
+		{"fetch:0", revealConfig{}, true},
+		{"fetch", revealConfig{}, true},
+		{"clock:5", revealConfig{}, true},
+		{"sometimes", revealConfig{}, true},
+	}
+	for _, tc := range cases {
+		got, err := parseReveal(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseReveal(%q) = %+v, %v; want %+v, error %t", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestRowsBefore(t *testing.T) {
+	date := time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC)
+	header, rows := splitRows([]byte("Time,Size\n1223,100\n1800,125\n2359,100\n0015,175\n1159,100\n,100\n"))
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	cases := []struct {
+		name          string
+		now           string
+		convectiveDay bool
+		want          int // rows visible; the untimed row always counts
+		wantLatest    string
+	}{
+		{"before the day starts", "2024-04-26T12:00:00Z", true, 1, ""},
+		{"first row", "2024-04-26T12:23:00Z", true, 2, "2024-04-26T12:23:00Z"},
+		{"evening", "2024-04-26T23:59:00Z", true, 4, "2024-04-26T23:59:00Z"},
+		{"past midnight rolls to the next date", "2024-04-27T00:15:00Z", true, 5, "2024-04-27T00:15:00Z"},
+		{"end of the convective day", "2024-04-27T11:59:00Z", true, 6, "2024-04-27T11:59:00Z"},
+		{"calendar day puts early rows first", "2024-04-26T12:00:00Z", false, 3, "2024-04-26T11:59:00Z"},
+		{"calendar day evening", "2024-04-26T23:59:00Z", false, 6, "2024-04-26T23:59:00Z"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			visible, latest := rowsBefore(header, rows, date, tc.convectiveDay, at(tc.now))
+			if len(visible) != tc.want {
+				t.Errorf("%d rows visible, want %d: %q", len(visible), tc.want, visible)
+			}
+			var wantLatest time.Time
+			if tc.wantLatest != "" {
+				wantLatest = at(tc.wantLatest)
+			}
+			if !latest.Equal(wantLatest) {
+				t.Errorf("latest = %s, want %s", latest, wantLatest)
+			}
+		})
+	}
+}
+
+func TestRowsBeforeWithoutTimeColumn(t *testing.T) {
+	header, rows := splitRows([]byte("Speed,Location\n65,Here\n70,There\n"))
+	visible, latest := rowsBefore(header, rows, time.Now(), true, time.Time{})
+	if len(visible) != len(rows) || !latest.IsZero() {
+		t.Errorf("rowsBefore = %d rows, %s; want every row and no time", len(visible), latest)
+	}
+}
+
+func TestRevealerFetch(t *testing.T) {
+	t0 := time.Date(2024, 4, 26, 18, 0, 0, 0, time.UTC)
+	rv := newRevealer()
+
+	cases := []struct {
+		name        string
+		file        string
+		step, total int
+		wantRows    int
+		wantChanged time.Time
+	}{
+		{"first fetch reveals one step", "a", 2, 5, 2, t0},
+		{"second fetch grows", "a", 2, 5, 4, t0.Add(time.Minute)},
+		{"third fetch caps at the total", "a", 2, 5, 5, t0.Add(2 * time.Minute)},
+		{"fetch after the cap keeps the change time", "a", 2, 5, 5, t0.Add(2 * time.Minute)},
+		{"files count separately", "b", 2, 5, 2, t0.Add(4 * time.Minute)},
+		{"a grown file is revealed further", "a", 2, 9, 9, t0.Add(5 * time.Minute)},
+		{"empty file", "c", 2, 0, 0, time.Time{}},
+	}
+	for i, tc := range cases {
+		now := t0.Add(time.Duration(i) * time.Minute)
+		rows, changed := rv.fetch(tc.file, tc.step, tc.total, now)
+		if rows != tc.wantRows || !changed.Equal(tc.wantChanged) {
+			t.Errorf("%s: fetch = %d, %s; want %d, %s", tc.name, rows, changed, tc.wantRows, tc.wantChanged)
+		}
+	}
+
+	if st := rv.state()["a"]; st.Fetches != 5 || st.Revealed != 9 || st.Rows != 9 {
+		t.Errorf("state of a = %+v", st)
+	}
+	rv.reset()
+	if rows, _ := rv.fetch("a", 2, 5, t0); rows != 2 {
+		t.Errorf("after reset fetch = %d rows, want 2", rows)
+	}
+}
+
+// TestReportForRevealHonoursConvectiveDay checks that a request's
+// convective_day option reaches the clock reveal as well as the rendering.
+func TestReportForRevealHonoursConvectiveDay(t *testing.T) {
+	key := fixtureKey{Date: "240426", Type: "hail"}
+	store := &fixtureStore{scenarios: map[string]map[fixtureKey]*fixture{}}
+	store.put(defaultScenario, &fixture{
+		key:  key,
+		date: time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC),
+		data: []byte("Time,Size\n1800,125\n0015,175\n"),
+	})
+	srv := &server{
+		fixtures:        store,
+		defaultScenario: defaultScenario,
+		serveMode:       serveModeExpanded,
+		convectiveDay:   true,
+		revealMode:      revealConfig{Mode: revealClock},
+		clock:           newClock(),
+	}
+
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"now=2024-04-26T23:00:00Z", "Time,Size\n2024-04-26T18:00:00Z,125\n"},
+		{"now=2024-04-26T23:00:00Z&convective_day=false", "Time,Size\n2024-04-26T18:00:00Z,125\n2024-04-26T00:15:00Z,175\n"},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/240426_rpts_hail.csv?"+tc.query, http.NoBody)
+		rep, err := srv.reportFor(r, defaultScenario, key)
+		if err != nil || rep == nil {
+			t.Fatalf("%s: reportFor = %v, %v", tc.query, rep, err)
+		}
+		if string(rep.data) != tc.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.query, rep.data, tc.want)
+		}
+	}
+}
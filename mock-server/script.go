@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+)
+
+// script is a set of per-route response sequences, e.g. "the first two
+// requests for the hail file return 503, the third returns the fixture".
+// Each route counts the requests it has matched; once its steps run out,
+// requests are served normally.
+type script struct {
+	mu     sync.Mutex
+	routes []*scriptRoute
+}
+
+// scriptFile is the JSON document loaded from SCRIPT_FILE or PUT /__admin/script.
+type scriptFile struct {
+	Routes []*scriptRoute `json:"routes"`
+}
+
+// scriptRoute matches request paths with a path.Match glob such as
+// "/*_rpts_hail.csv". The first matching route handles a request.
+type scriptRoute struct {
+	Path  string       `json:"path"`
+	Steps []scriptStep `json:"steps"`
+	hits  int
+}
+
+// scriptStep is one scripted response. A step with a Body or a non-2xx
+// Status replaces the fixture. Otherwise the fixture is served as usual, with
+// Status (if set) as its status and any Faults applied on top of the server
+// defaults, so "503, 503, then 200" ends by serving the fixture.
+type scriptStep struct {
+	Status int               `json:"status,omitempty"`
+	Body   string            `json:"body,omitempty"`
+	Faults map[string]string `json:"faults,omitempty"`
+	Repeat int               `json:"repeat,omitempty"` // times to use this step; default 1
+}
+
+// loadScript reads a script file. An empty path yields an empty script.
+func loadScript(file string) (*script, error) {
+	s := &script{}
+	if file == "" {
+		return s, nil
+	}
+	f, err := os.Open(file) //nolint:gosec // path comes from SCRIPT_FILE
+	if err != nil {
+		return nil, fmt.Errorf("opening script: %w", err)
+	}
+	defer f.Close()
+	if err := s.replace(f); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// replace parses a JSON script, validates it, and swaps it in with fresh counters.
+func (s *script) replace(r io.Reader) error {
+	var sf scriptFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sf); err != nil {
+		return fmt.Errorf("parsing script: %w", err)
+	}
+	for _, route := range sf.Routes {
+		if err := route.validate(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = sf.Routes
+	return nil
+}
+
+func (rt *scriptRoute) validate() error {
+	if rt.Path == "" {
+		return errors.New("script route has no path")
+	}
+	if _, err := path.Match(rt.Path, ""); err != nil {
+		return fmt.Errorf("script route %q: %w", rt.Path, err)
+	}
+	for i, step := range rt.Steps {
+		if step.Repeat < 0 {
+			return fmt.Errorf("script route %q step %d: negative repeat", rt.Path, i)
+		}
+		if step.Status != 0 && !bodyStatus(step.Status) {
+			return fmt.Errorf("script route %q step %d: invalid status %d: want 200-599 other than 204, 205 and 304", rt.Path, i, step.Status)
+		}
+		if _, err := (faultConfig{}).withOptions(step.Faults); err != nil {
+			return fmt.Errorf("script route %q step %d: %w", rt.Path, i, err)
+		}
+	}
+	return nil
+}
+
+// bodyStatus reports whether a step may use status: one in 200–599 that can
+// carry a body, since every step sends either the fixture or a Body.
+func bodyStatus(status int) bool {
+	switch status {
+	case http.StatusNoContent, http.StatusResetContent, http.StatusNotModified:
+		return false
+	}
+	return status >= 200 && status <= 599
+}
+
+// replacesFixture reports whether the step sends its own response instead
+// of the fixture.
+func (st scriptStep) replacesFixture() bool {
+	return st.Body != "" || st.Status >= 300
+}
+
+// next advances the first route matching urlPath and returns its current
+// step. It returns false when no route matches or the route's steps are used up.
+func (s *script) next(urlPath string) (scriptStep, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, route := range s.routes {
+		if ok, _ := path.Match(route.Path, urlPath); !ok {
+			continue
+		}
+		n := route.hits
+		route.hits++
+		return route.step(n)
+	}
+	return scriptStep{}, false
+}
+
+// step returns the step serving the route's n-th request (0-based).
+func (rt *scriptRoute) step(n int) (scriptStep, bool) {
+	for _, st := range rt.Steps {
+		repeat := max(st.Repeat, 1)
+		if n < repeat {
+			return st, true
+		}
+		n -= repeat
+	}
+	return scriptStep{}, false
+}
+
+// reset zeroes every route's counter.
+func (s *script) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, route := range s.routes {
+		route.hits = 0
+	}
+}
+
+// scriptRouteState is the admin view of a route and its progress.
+type scriptRouteState struct {
+	Path      string       `json:"path"`
+	Steps     []scriptStep `json:"steps"`
+	Hits      int          `json:"hits"`
+	Exhausted bool         `json:"exhausted"`
+}
+
+func (s *script) state() []scriptRouteState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]scriptRouteState, 0, len(s.routes))
+	for _, route := range s.routes {
+		_, active := route.step(route.hits)
+		out = append(out, scriptRouteState{
+			Path:      route.Path,
+			Steps:     route.Steps,
+			Hits:      route.hits,
+			Exhausted: !active,
+		})
+	}
+	return out
+}
+
+// handleGetScript shows the loaded script and each route's hit count.
+func (s *server) handleGetScript(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"routes": s.script.state()})
+}
+
+// handlePutScript replaces the script and resets its counters.
+func (s *server) handlePutScript(w http.ResponseWriter, r *http.Request) {
+	if err := s.script.replace(r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"routes": s.script.state()})
+}
+
+// handleResetScript rewinds every route to its first step.
+func (s *server) handleResetScript(w http.ResponseWriter, _ *http.Request) {
+	s.script.reset()
+	writeJSON(w, http.StatusOK, map[string]any{"routes": s.script.state()})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestScriptRouteStep(t *testing.T) {
+	route := &scriptRoute{
+		Path: "/240426_rpts_torn.csv",
+		Steps: []scriptStep{
+			{Status: http.StatusServiceUnavailable, Repeat: 2},
+			{Faults: map[string]string{"latency": "2s"}},
+			{Status: http.StatusNotFound, Repeat: 0}, // 0 means once
+			{},
+		},
+	}
+
+	cases := []struct {
+		n          int
+		wantStatus int
+		wantFaults bool
+		wantOK     bool
+	}{
+		{0, http.StatusServiceUnavailable, false, true},
+		{1, http.StatusServiceUnavailable, false, true},
+		{2, 0, true, true},
+		{3, http.StatusNotFound, false, true},
+		{4, 0, false, true},
+		{5, 0, false, false},
+		{100, 0, false, false},
+	}
+	for _, tc := range cases {
+		st, ok := route.step(tc.n)
+		if ok != tc.wantOK || st.Status != tc.wantStatus || (st.Faults != nil) != tc.wantFaults {
+			t.Errorf("step(%d) = %+v, %t, want status %d, faults %t, %t", tc.n, st, ok, tc.wantStatus, tc.wantFaults, tc.wantOK)
+		}
+	}
+}
+
+func TestScriptRouteStepEmpty(t *testing.T) {
+	route := &scriptRoute{Path: "/240426_rpts_torn.csv"}
+	if st, ok := route.step(0); ok {
+		t.Errorf("step(0) on a route with no steps = %+v, want none", st)
+	}
+}
+
+func TestScriptRouteValidateStatus(t *testing.T) {
+	cases := []struct {
+		status int
+		ok     bool
+	}{
+		{0, true},
+		{200, true},
+		{203, true},
+		{404, true},
+		{503, true},
+		{599, true},
+		{100, false},
+		{199, false},
+		{204, false},
+		{205, false},
+		{304, false},
+		{600, false},
+	}
+	for _, tc := range cases {
+		route := &scriptRoute{Path: "/*.csv", Steps: []scriptStep{{Status: tc.status}}}
+		if err := route.validate(); (err == nil) != tc.ok {
+			t.Errorf("validate with status %d: err = %v, want ok %t", tc.status, err, tc.ok)
+		}
+	}
+}
+
+func TestFaultsFor(t *testing.T) {
+	srv := &server{
+		faults: faultConfig{ErrorStatus: http.StatusServiceUnavailable},
+		script: &script{routes: []*scriptRoute{{
+			Path: "/*_rpts_hail.csv",
+			Steps: []scriptStep{
+				{Status: http.StatusServiceUnavailable, Repeat: 2},
+				{Status: http.StatusOK},
+				{Status: http.StatusNonAuthoritativeInfo},
+				{Status: http.StatusOK, Body: "scripted\n"},
+				{Faults: map[string]string{"latency": "2s"}},
+			},
+		}}},
+	}
+
+	cases := []struct {
+		name        string
+		wantStep    bool
+		wantReplace bool
+		wantStatus  int
+		wantLatency time.Duration
+	}{
+		{"first 503", true, true, http.StatusServiceUnavailable, 0},
+		{"second 503", true, true, http.StatusServiceUnavailable, 0},
+		{"200 without a body serves the fixture", true, false, http.StatusOK, 0},
+		{"203 without a body serves the fixture", true, false, http.StatusNonAuthoritativeInfo, 0},
+		{"200 with a body replaces the fixture", true, true, http.StatusOK, 0},
+		{"faults only", true, false, 0, 2 * time.Second},
+		{"script used up", false, false, 0, 0},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/240426_rpts_hail.csv", http.NoBody)
+		faults, step, err := srv.faultsFor(r)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if (step != nil) != tc.wantStep {
+			t.Errorf("%s: step = %+v, want step %t", tc.name, step, tc.wantStep)
+			continue
+		}
+		if step != nil && (step.replacesFixture() != tc.wantReplace || step.Status != tc.wantStatus) {
+			t.Errorf("%s: step = %+v, replaces %t, want status %d, replaces %t", tc.name, step, step.replacesFixture(), tc.wantStatus, tc.wantReplace)
+		}
+		if faults.LatencyMax != tc.wantLatency {
+			t.Errorf("%s: latency = %s, want %s", tc.name, faults.LatencyMax, tc.wantLatency)
+		}
+	}
+}
+
+// TestScriptedStatusThenFixture checks the end-to-end sequence a retry test
+// scripts: two 503s, then a 200 step that serves the fixture itself.
+func TestScriptedStatusThenFixture(t *testing.T) {
+	const body = "Time,Size\n1510,125\n"
+	store := &fixtureStore{scenarios: map[string]map[fixtureKey]*fixture{}}
+	store.put(defaultScenario, &fixture{
+		key:  fixtureKey{Date: "240426", Type: "hail"},
+		date: time.Date(2024, 4, 26, 0, 0, 0, 0, time.UTC),
+		data: []byte(body),
+	})
+	srv := &server{
+		fixtures:        store,
+		defaultScenario: defaultScenario,
+		serveMode:       serveModeRaw,
+		revealMode:      revealConfig{Mode: revealOff},
+		faults:          faultConfig{ErrorStatus: http.StatusServiceUnavailable},
+		clock:           newClock(),
+		script: &script{routes: []*scriptRoute{{
+			Path:  "/*_rpts_hail.csv",
+			Steps: []scriptStep{{Status: http.StatusServiceUnavailable, Repeat: 2}, {Status: http.StatusOK}},
+		}}},
+	}
+
+	for i, want := range []struct {
+		status int
+		body   string
+	}{
+		{http.StatusServiceUnavailable, "Service Unavailable\n"},
+		{http.StatusServiceUnavailable, "Service Unavailable\n"},
+		{http.StatusOK, body},
+	} {
+		w := httptest.NewRecorder()
+		srv.handleReport(w, httptest.NewRequest(http.MethodGet, "/240426_rpts_hail.csv", http.NoBody))
+		if w.Code != want.status || w.Body.String() != want.body {
+			t.Errorf("request %d = %d %q, want %d %q", i+1, w.Code, w.Body.String(), want.status, want.body)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// server serves NOAA-format report files from the fixture store.
+type server struct {
+	fixtures *fixtureStore
+
+	// defaultScenario is served when a request does not select one.
+	defaultScenario string
+
+	// fallbackDate, when set, is served for dates that have no fixture
+	// instead of a 404. Lets a collector polling "today" hit fixed data.
+	fallbackDate string
+
+	// serveMode is serveModeExpanded or serveModeRaw. Requests may override it.
+	serveMode string
+
+	// convectiveDay stamps 0000–1159 rows with the day after the file's date,
+	// matching SPC's 12Z-to-12Z daily files. Requests may override it.
+	convectiveDay bool
+
+	// faults are the FAULT_* defaults; scripted steps and requests may override them.
+	faults faultConfig
+
+	// script holds scripted response sequences loaded from SCRIPT_FILE.
+	script *script
+
+	// journal records recent requests for /__admin/requests.
+	journal *journal
+
+	// generator holds the defaults for the generated scenario.
+	generator generatorConfig
+
+	// revealMode makes files grow over time or per fetch. Requests may override it.
+	revealMode revealConfig
+
+	// revealer tracks per-file progress for the fetch reveal mode.
+	revealer *revealer
+
+	// clock is the virtual "now" for time-dependent behaviour.
+	clock *clock
+}
+
+// handleReport serves the fixture matching the requested scenario, date, and
+// type, or a combined file assembled from the date's fixtures.
+// In expanded mode the Time column is rewritten from HHMM to full ISO 8601
+// using the fixture's date (and convective-day rollover) so the collector
+// produces correct historical timestamps; raw mode serves the file untouched.
+func (s *server) handleReport(w http.ResponseWriter, r *http.Request) {
+	now, err := s.nowFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, ok := requestedReport(r, now)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	faults, step, err := s.faultsFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !faults.before(w, r) {
+		return
+	}
+	if step != nil && step.replacesFixture() {
+		writeScriptedStep(w, r, *step)
+		return
+	}
+
+	scenario := s.scenarioFor(r)
+	rep, err := s.reportFor(r, scenario, key)
+	switch {
+	case errors.Is(err, errUnknownScenario):
+		http.Error(w, fmt.Sprintf("unknown scenario %s", scenario), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case rep == nil:
+		log.Printf("no fixture for %s in scenario %s", key, scenario)
+		writeNOAANotFound(w)
+		return
+	}
+
+	etag := etagFor(rep.data, rep.modTime)
+	setValidators(w, etag, rep.modTime)
+	if notModified(r, etag, rep.modTime) {
+		log.Printf("not modified: %s/%s for request %s", scenario, key, r.URL.Path)
+		writeNotModified(w)
+		return
+	}
+
+	status := http.StatusOK
+	if step != nil && step.Status != 0 {
+		status = step.Status
+	}
+	log.Printf("serving %s/%s for request %s with status %d", scenario, key, r.URL.Path, status)
+	w.Header().Set("Content-Type", "text/csv")
+	if err := faults.write(w, r, status, rep.data); err != nil {
+		log.Printf("error writing response: %v", err)
+	}
+}
+
+// requestedReport resolves the report file name in the request path,
+// including today/yesterday aliases relative to now.
+func requestedReport(r *http.Request, now time.Time) (fixtureKey, bool) {
+	name := r.PathValue("file")
+	if name == "" {
+		name = strings.TrimPrefix(r.URL.Path, "/")
+	}
+	return resolveReport(name, now)
+}
+
+// reportKey resolves the report a request asks for, falling back to the
+// server clock if the request's now option is invalid.
+func (s *server) reportKey(r *http.Request) (fixtureKey, bool) {
+	now, err := s.nowFor(r)
+	if err != nil {
+		now = s.clock.now()
+	}
+	return resolveReport(path.Base(r.URL.Path), now)
+}
+
+// faultsFor advances the script for this request and combines the server's
+// fault defaults with the scripted step's and the request's overrides. The
+// returned step is non-nil when the script supplies this response; see
+// scriptStep.replacesFixture for whether the fixture is still served.
+func (s *server) faultsFor(r *http.Request) (faultConfig, *scriptStep, error) {
+	step, scripted := s.script.next(r.URL.Path)
+	faults, err := s.faults.withOptions(step.Faults)
+	if err != nil {
+		return faultConfig{}, nil, err
+	}
+	if faults, err = faults.withRequest(r); err != nil {
+		return faultConfig{}, nil, err
+	}
+	if !scripted {
+		return faults, nil, nil
+	}
+	return faults, &step, nil
+}
+
+// errUnknownScenario is returned by fixtureFor when no such scenario exists.
+var errUnknownScenario = errors.New("unknown scenario")
+
+// fixtureFor resolves the fixture a report request should be served. The
+// generated scenario synthesizes one; other scenarios look it up in the
+// store, falling back to FALLBACK_DATE if configured. It returns nil and no
+// error when the date has no data.
+func (s *server) fixtureFor(r *http.Request, scenario string, key fixtureKey) (*fixture, error) {
+	if scenario == generatedScenario {
+		g, err := s.generatorFor(r)
+		if err != nil {
+			return nil, err
+		}
+		return generateFixture(g, key), nil
+	}
+
+	if !s.fixtures.hasScenario(scenario) {
+		return nil, errUnknownScenario
+	}
+	f, ok := s.fixtures.lookup(scenario, key)
+	if !ok && s.fallbackDate != "" {
+		f, ok = s.fixtures.lookup(scenario, fixtureKey{Date: s.fallbackDate, Type: key.Type})
+	}
+	if !ok {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// Serve modes control how fixture CSVs are rewritten before serving.
+const (
+	// serveModeExpanded rewrites HHMM times to ISO 8601. This is the
+	// compatibility mode the collector has historically been tested against.
+	serveModeExpanded = "expanded"
+	// serveModeRaw serves fixtures byte-for-byte, exactly as NOAA would.
+	serveModeRaw = "raw"
+)
+
+func parseServeMode(v string) (string, error) {
+	switch v {
+	case serveModeExpanded, serveModeRaw:
+		return v, nil
+	default:
+		return "", fmt.Errorf("serve mode %q: want %s or %s", v, serveModeExpanded, serveModeRaw)
+	}
+}
+
+// render produces the response body for a fixture, applying the server's
+// serve mode setting or the request's override. In expanded mode times are
+// stamped per convectiveDay.
+func (s *server) render(r *http.Request, f *fixture, convectiveDay bool) ([]byte, error) {
+	mode := s.serveMode
+	if v := requestOption(r, "serve_mode"); v != "" {
+		var err error
+		if mode, err = parseServeMode(v); err != nil {
+			return nil, err
+		}
+	}
+	if mode == serveModeRaw {
+		return f.data, nil
+	}
+	return expandTimes(f.data, f.date, convectiveDay), nil
+}
+
+// convectiveDayFor reports whether a request's rows run 12Z to 12Z: the
+// convective_day option if given, otherwise the server's setting.
+func (s *server) convectiveDayFor(r *http.Request) (bool, error) {
+	v := requestOption(r, "convective_day")
+	if v == "" {
+		return s.convectiveDay, nil
+	}
+	convectiveDay, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New("convective_day must be true or false")
+	}
+	return convectiveDay, nil
+}
+
+// writeScriptedStep sends a scripted status and body in place of the fixture.
+func writeScriptedStep(w http.ResponseWriter, r *http.Request, step scriptStep) {
+	status := step.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body := step.Body
+	if body == "" {
+		body = http.StatusText(status) + "\n"
+	}
+	log.Printf("script: responding %d to %s", status, r.URL.Path)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, body)
+}
+
+// scenarioFor picks the fixture set for a request: the /scenarios/{name}/
+// path prefix wins, then the scenario query/header option, then the default.
+func (s *server) scenarioFor(r *http.Request) string {
+	if name := r.PathValue("scenario"); name != "" {
+		return name
+	}
+	if name := requestOption(r, "scenario"); name != "" {
+		return name
+	}
+	return s.defaultScenario
+}
+
+// requestOption returns a per-request override from the query string or the
+// matching X-Mock-* header, e.g. "scenario" reads ?scenario= or X-Mock-Scenario.
+// The query string takes precedence.
+func requestOption(r *http.Request, name string) string {
+	if v := r.URL.Query().Get(name); v != "" {
+		return v
+	}
+	return r.Header.Get("X-Mock-" + strings.ReplaceAll(name, "_", "-"))
+}
+
+// noaaNotFoundBody mirrors the Apache 404 page SPC returns for dates with no reports file.
+const noaaNotFoundBody = `<!DOCTYPE HTML PUBLIC "-//IETF//DTD HTML 2.0//EN">
+<html><head>
+<title>404 Not Found</title>
+</head><body>
+<h1>Not Found</h1>
+<p>The requested URL was not found on this server.</p>
+</body></html>
+`
+
+// writeNOAANotFound responds the way NOAA does when a report file does not exist.
+func writeNOAANotFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = io.WriteString(w, noaaNotFoundBody)
+}
+
+// writeJSON encodes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error writing response: %v", err)
+	}
+}